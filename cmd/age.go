@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/tree"
+)
+
+// Age estimates the ages of the branches of a tree or of the common
+// ancestors of groups of kits from the number of SNPs and the
+// callable lengths of the kits.
+// cmdLine: command line parameters without the subcommand.
+func Age(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in          = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		beds        = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		treeFile    = flags.String("tree", "", "Input tree file, used instead of parameter in. Requires parameter length.")
+		treeFmt     = flags.String("treeformat", "auto", "Format of the tree file: newick (also NEXUS), yfull (JSON), yfullcsv, ftdna (JSON) or auto.")
+		out         = flags.String("out", "", "Output file.")
+		format      = flags.String("format", "text", "Output format: text (one line per branch), tree (text), svg or dot.")
+		maxSNPs     = flags.Int("maxsnps", 5, "Maximum number of SNPs shown for a branch in svg and dot format. 0 shows all SNPs.")
+		rate        = flags.Float64("rate", tree.DefaultRate, "Mutation rate in SNPs per base pair and year.")
+		confidence  = flags.Float64("confidence", 0.95, "Level of the confidence interval.")
+		method      = flags.String("method", "poisson", "Confidence interval: poisson or gamma.")
+		length      = flags.Int("length", 0, "Callable length in base pairs for branches without BED files.")
+		groups      = flags.String("groups", "", "File with groups of kits. Each line contains a name, a colon and kit names separated by commas.")
+		calibration = flags.String("calibration", "", "File with known ages of common ancestors. Each line contains the age in years, a colon and kit names separated by commas.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" && *treeFile == "" {
+		fmt.Printf("Parameter in or tree not specified.\n")
+		os.Exit(1)
+	}
+	switch *format {
+	case "text", "tree", "svg", "dot":
+	default:
+		fmt.Printf("Parameter format must be text, tree, svg or dot.\n")
+		os.Exit(1)
+	}
+	if *method != "poisson" && *method != "gamma" {
+		fmt.Printf("Parameter method must be poisson or gamma.\n")
+		os.Exit(1)
+	}
+	if *confidence <= 0 || *confidence >= 1 {
+		fmt.Printf("Parameter confidence must be between 0 and 1.\n")
+		os.Exit(1)
+	}
+	if *treeFile != "" && *length <= 0 {
+		fmt.Printf("Parameter length must be specified for parameter tree.\n")
+		os.Exit(1)
+	}
+
+	var t *tree.Tree
+	var err error
+	if *treeFile != "" {
+		t, err = readTree(*treeFile, *treeFmt)
+		checkFatal(err, "Error reading tree file")
+	} else {
+		t, err = buildTree(*in, *beds)
+		checkFatal(err, "Error reading kits")
+	}
+	dating := tree.Dating{Rate: *rate, Confidence: *confidence, Gamma: *method == "gamma", Length: *length}
+
+	// Calibrate mutation rate.
+	if *calibration != "" {
+		points, err := readKitGroups(*calibration)
+		checkFatal(err, "Error reading calibration file")
+		var cal []tree.Calibration
+		for _, p := range points {
+			years, err := strconv.ParseFloat(p.name, 64)
+			checkFatal(err, "Error reading calibration file")
+			cal = append(cal, tree.Calibration{Kits: p.kits, Years: years})
+		}
+		dating.Rate, err = t.Calibrate(cal, dating)
+		checkFatal(err, "Error calibrating mutation rate")
+	}
+	t.EstimateAges(dating)
+	dated := false
+	t.Root.Walk(func(node *tree.Node, depth int) {
+		dated = dated || node.Age != nil
+	})
+	if !dated && *length <= 0 {
+		fmt.Printf("Callable lengths are unknown. Parameter beds or length must be specified.\n")
+		os.Exit(1)
+	}
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	switch *format {
+	case "tree":
+		err = t.Write(outfile)
+	case "svg":
+		err = t.WriteSVG(outfile, tree.Style{MaxSNPs: *maxSNPs, Ages: true})
+	case "dot":
+		err = t.WriteDOT(outfile, tree.Style{MaxSNPs: *maxSNPs, Ages: true})
+	}
+	if *format != "text" {
+		checkFatal(err, "Error writing tree")
+		return
+	}
+	w := bufio.NewWriter(outfile)
+	if *calibration != "" {
+		fmt.Fprintf(w, "Calibrated rate: %g SNPs per base pair and year\r\n", dating.Rate)
+	}
+	if *groups != "" {
+		kitGroups, err := readKitGroups(*groups)
+		checkFatal(err, "Error reading groups file")
+		for _, g := range kitGroups {
+			node, err := t.MRCA(g.kits)
+			checkFatal(err, "Error finding common ancestor of group "+g.name)
+			writeAge(w, g.name, node.Age, *confidence)
+		}
+	} else {
+		t.Root.Walk(func(node *tree.Node, depth int) {
+			if node.Age != nil {
+				writeAge(w, node.Label, node.Age, *confidence)
+			}
+		})
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing ages")
+}
+
+// writeAge writes a line with the age of a branch or group.
+func writeAge(w *bufio.Writer, name string, age *tree.Age, confidence float64) {
+	if age == nil {
+		fmt.Fprintf(w, "%s: unknown age\r\n", name)
+		return
+	}
+	fmt.Fprintf(w, "%s: %.0f years (%.0f%% CI %.0f-%.0f), %d SNPs on %d lineages, %d bp\r\n",
+		name, age.Years, confidence*100, age.Lower, age.Upper, age.Mutations, age.Lineages, age.Callable)
+}
+
+// kitGroup is a named group of kits.
+type kitGroup struct {
+	name string
+	kits []string
+}
+
+// readKitGroups reads groups of kits from a text file. Each line
+// contains a name, a colon and kit names separated by commas.
+// Empty lines and lines starting with # are ignored.
+func readKitGroups(filename string) ([]kitGroup, error) {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer infile.Close()
+
+	var groups []kitGroup
+	scanner := bufio.NewScanner(infile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return nil, errors.New(fmt.Sprintf("missing colon in line %q", line))
+		}
+		g := kitGroup{name: strings.TrimSpace(line[:i])}
+		for _, kit := range strings.Split(line[i+1:], ",") {
+			if kit = strings.TrimSpace(kit); kit != "" {
+				g.kits = append(g.kits, kit)
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups, scanner.Err()
+}
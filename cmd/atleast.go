@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// AtLeast calculates the SNPs that are contained in at least k
+// (or exactly k) of the files specified by the parameter in.
+// cmdLine: command line parameters without the subcommand.
+func AtLeast(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in     = flags.String("in", "", "Input list of CSV files separated by commas.")
+		out    = flags.String("out", "", "Output file in CSV format.")
+		k      = flags.Int("k", 2, "Minimum number of files that must contain an SNP.")
+		exact  = flags.Bool("exact", false, "If exact=true an SNP must be contained in exactly k files.")
+		counts = flags.Bool("counts", false, "If counts=true the number of files and the names of the files containing an SNP are added to the output.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in for input files not specified.\n")
+		os.Exit(1)
+	}
+	if *k < 1 {
+		fmt.Printf("Parameter k must be at least 1.\n")
+		os.Exit(1)
+	}
+
+	// Parse filename parameter.
+	filenames, err := parameterToFilenames(*in, ".csv")
+	checkFatal(err, "Error parsing parameter in")
+	if len(filenames) == 0 {
+		fmt.Printf("No files found for input parameter in.\n")
+		os.Exit(1)
+	}
+
+	// Count occurrences.
+	sets := make([]snp.SNPs, len(filenames))
+	annotations := make(snp.Annotations)
+	for i, filename := range filenames {
+		var a snp.Annotations
+		sets[i], a, err = snp.ReadAnnotatedCSV(filename)
+		checkFatal(err, "Error reading CSV file")
+		annotations.Merge(a)
+	}
+	union, indices := snp.Occurrences(sets)
+	var snps snp.SNPs
+	var files [][]int
+	for i, s := range union {
+		if len(indices[i]) >= *k && (!*exact || len(indices[i]) == *k) {
+			snps = append(snps, s)
+			files = append(files, indices[i])
+		}
+	}
+
+	// Output SNPs.
+	if !*counts {
+		err := writeSNPs(snps, annotations, *out)
+		checkFatal(err, "Error writing SNPs to CSV output file")
+		return
+	}
+
+	// Output SNPs with counts and file names.
+	// Columns: Pos, Ref, Alt, count, files separated by semicolons
+	// and for annotated SNPs Name and Comment.
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := csv.NewWriter(outfile)
+	w.UseCRLF = true
+	for i, s := range snps {
+		names := make([]string, len(files[i]))
+		for j, f := range files[i] {
+			names[j] = filepath.Base(filenames[f])
+		}
+		record := []string{strconv.Itoa(s.Pos), s.Ref, s.Alt, strconv.Itoa(len(names)), strings.Join(names, ";")}
+		if len(annotations) > 0 {
+			a := annotations[s]
+			record = append(record, a.Name, a.Comment)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	checkFatal(w.Error(), "Error writing SNPs to CSV output file")
+}
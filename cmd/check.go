@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+	"github.com/yogischogi/phylosnip/tree"
+)
+
+// Check checks whether a new kit fits into the tree of a project.
+// The report contains the placement of the kit, branches that would
+// be split, conflicting SNPs, private SNPs of other kits that are
+// shared with the new kit and the novel SNPs of the new kit.
+// If an SNP data base is given, the kits are polarized with it
+// like in Place.
+// cmdLine: command line parameters without the subcommand.
+func Check(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		kitFile   = flags.String("kit", "", "CSV file of the new kit.")
+		kitBed    = flags.String("kitbed", "", "BED file with the callable regions of the new kit.")
+		negatives = flags.String("negatives", "", "CSV file with the ancestral SNPs of the new kit, used if the BED file is missing.")
+		in        = flags.String("in", "", "Input list of project kit CSV files or directories separated by commas.")
+		beds      = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		isoggdb   = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format, used to polarize the calls.")
+		db        = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache   = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+		out       = flags.String("out", "", "Output file for the report.")
+	)
+	flags.Parse(cmdLine)
+
+	if *kitFile == "" {
+		fmt.Printf("Parameter kit not specified.\n")
+		os.Exit(1)
+	}
+	if *in == "" {
+		fmt.Printf("Parameter in for project kits not specified.\n")
+		os.Exit(1)
+	}
+	if *kitBed == "" && *negatives == "" {
+		fmt.Printf("Parameter kitbed or negatives not specified.\n")
+		os.Exit(1)
+	}
+
+	// Read the new kit.
+	name := strings.TrimSuffix(filepath.Base(*kitFile), filepath.Ext(*kitFile))
+	derived, annotations, err := snp.ReadAnnotatedCSV(*kitFile)
+	checkFatal(err, "Error reading kit file")
+	var callable snp.BEDRegions
+	if *kitBed != "" {
+		callable, err = snp.ReadBED(*kitBed)
+		checkFatal(err, "Error reading BED file")
+	}
+	var ancestral snp.SNPs
+	if *negatives != "" {
+		var a snp.Annotations
+		ancestral, a, err = snp.ReadAnnotatedCSV(*negatives)
+		checkFatal(err, "Error reading negatives file")
+		annotations.Merge(a)
+	}
+
+	// Build the project tree without the new kit.
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
+	kits, err := readKits(*in, *beds)
+	checkFatal(err, "Error reading kits")
+	names := make([]string, 0, len(kits))
+	sets := make([]snp.SNPs, 0, len(kits))
+	callables := make([]snp.BEDRegions, 0, len(kits))
+	treeAnnotations := make(snp.Annotations)
+	for _, k := range kits {
+		if k.name == name {
+			continue
+		}
+		names = append(names, k.name)
+		sets = append(sets, k.snps)
+		callables = append(callables, k.callable)
+		treeAnnotations.Merge(k.annotations)
+	}
+	if len(names) == 0 {
+		fmt.Printf("No project kits found for input parameter in.\n")
+		os.Exit(1)
+	}
+	if snpDB != nil {
+		sets = snpDB.PolarizeKits(sets, callables)
+		// SNPs in data base orientation are named after the data base.
+		for _, set := range sets {
+			for _, s := range set {
+				if _, exists := treeAnnotations[s]; exists {
+					continue
+				}
+				if rec, exists := snpDB.EntryByKey(s); exists && rec.Name != "" {
+					treeAnnotations.Add(s, snp.Annotation{Name: rec.Name})
+				}
+			}
+		}
+	}
+	t := tree.Build(snp.NewMatrix(names, sets, callables), treeAnnotations)
+	result := t.Check(tree.NewCalls(derived, ancestral, annotations, callable, snpDB))
+
+	// Write report.
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := bufio.NewWriter(outfile)
+	writePlacement(w, name, result.Placement)
+	if callable == nil {
+		w.WriteString("Ancestral state: unknown except for negative SNPs, because the kit has no BED file.\r\n")
+	}
+	writeBranchCalls(w, "Branches split by the kit", result.Splits)
+	writeBranchCalls(w, "Conflicting branches", result.Conflicts)
+	writeBranchCalls(w, "Private SNPs of other kits shared with the kit", result.SharedPrivates)
+	fmt.Fprintf(w, "Novel SNPs: %d\r\n", len(result.Novel))
+	for _, s := range result.Novel {
+		w.WriteString("  " + s.String())
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing report")
+}
+
+// writeBranchCalls writes a section of a report with the
+// derived and ancestral SNPs of branches.
+func writeBranchCalls(w *bufio.Writer, title string, calls []tree.BranchCalls) {
+	fmt.Fprintf(w, "%s: %d\r\n", title, len(calls))
+	for _, bc := range calls {
+		fmt.Fprintf(w, "  %s: %d derived, %d ancestral, %d no call\r\n",
+			bc.Node.Label, len(bc.Derived), len(bc.Ancestral), len(bc.Unknown))
+		if len(bc.Derived) > 0 {
+			w.WriteString("    derived: " + strings.Join(bc.Derived, ", ") + "\r\n")
+		}
+		if len(bc.Ancestral) > 0 {
+			w.WriteString("    ancestral: " + strings.Join(bc.Ancestral, ", ") + "\r\n")
+		}
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
 )
 
 // checkFatal checks for an error. In case the
@@ -19,13 +22,88 @@ func checkFatal(err error, msg string) {
 	}
 }
 
+// readDB reads the ISOGG SNP data base from a CSV file.
+// A binary cache file is used to speed up reading unless
+// nocache is true.
+func readDB(filename string, nocache bool) (*snp.DB, error) {
+	db := snp.NewDB()
+	var err error
+	if nocache {
+		err = db.ReadISOGGcsv(filename)
+	} else {
+		err = db.ReadISOGGcached(filename)
+	}
+	return db, err
+}
+
+// parameterToDBSources parses a command line parameter for SNP
+// data base sources. The parameter contains a list of sources separated
+// by commas. Each source is a filename which may be prefixed by a
+// format and a colon, for example isogg:snps_hg38.csv, csv:private.csv
+// or yfull:ytree.json. The default format is isogg. The filename is used
+// as source name.
+func parameterToDBSources(sourcesParameter string) (sources []snp.DBSource, err error) {
+	for _, param := range strings.Split(sourcesParameter, ",") {
+		source := snp.DBSource{Name: param, Format: snp.FormatISOGG, Filename: param}
+		if i := strings.Index(param, ":"); i > 0 {
+			switch format := strings.ToLower(param[:i]); format {
+			case snp.FormatISOGG, snp.FormatCSV, snp.FormatYFull:
+				source.Format = format
+				source.Filename = param[i+1:]
+				source.Name = source.Filename
+			default:
+				return sources, errors.New(fmt.Sprintf("unknown format %s in data base source %s", format, param))
+			}
+		}
+		if source.Filename == "" {
+			return sources, errors.New(fmt.Sprintf("missing filename in data base source %s", param))
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// dbFromParameters reads the SNP data base from the command line
+// parameters isoggdb and sources. isoggdb is a single ISOGG CSV file
+// and sources is a list of sources as described in parameterToDBSources.
+// If both parameters are given, isoggdb has the highest priority.
+// If both parameters are empty, nil is returned.
+func dbFromParameters(isoggdb, sources string, nocache bool) (*snp.DB, []snp.DBConflict, error) {
+	if sources == "" {
+		if isoggdb == "" {
+			return nil, nil, nil
+		}
+		db, err := readDB(isoggdb, nocache)
+		return db, nil, err
+	}
+	dbSources, err := parameterToDBSources(sources)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isoggdb != "" {
+		isogg := snp.DBSource{Name: isoggdb, Format: snp.FormatISOGG, Filename: isoggdb}
+		dbSources = append([]snp.DBSource{isogg}, dbSources...)
+	}
+	return snp.ReadDBSources(dbSources, !nocache)
+}
+
 // parameterToFilenames parses a command line parameter for filenames.
 // The parameter containes a list of filenames separated by commas.
 // If a filename is a directory parameterToFilenames returns all files
 // within that directory that satisfy the given extension ext.
+// A kit ID prefixed by @ denotes the SNP file of a kit in the
+// project workspace and @ alone all kits of the workspace.
 func parameterToFilenames(filesParameter string, ext string) (filenames []string, err error) {
 	files := strings.Split(filesParameter, ",")
 	for _, file := range files {
+		if strings.HasPrefix(file, "@") {
+			kitFiles, err := workspaceKitFiles(file[1:])
+			if err != nil {
+				return filenames, err
+			}
+			filenames = append(filenames, kitFiles...)
+			continue
+		}
 		fileInfo, err := os.Stat(file)
 		switch {
 		case err != nil:
@@ -67,6 +145,7 @@ func namesWithExt(dirName string, ext string) (filenames []string, err error) {
 			filenames = append(filenames, filename)
 		}
 	}
+	sort.Strings(filenames)
 	return filenames, err
 }
 
@@ -74,6 +153,24 @@ func namesWithExt(dirName string, ext string) (filenames []string, err error) {
 // maps all input names to corresponding output names.
 // If out is an empty string, all input files are mapped to empty strings.
 func inToOutFilenames(in, inExt, out, outExt string) (inNames, outNames []string, err error) {
+	// Kits of the project workspace are mapped to files named
+	// after their IDs in the directory out.
+	if strings.HasPrefix(in, "@") {
+		inNames, err = parameterToFilenames(in, inExt)
+		if err != nil {
+			return inNames, outNames, err
+		}
+		for _, name := range inNames {
+			outName := ""
+			if out != "" {
+				base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+				outName = filepath.Join(out, base+outExt)
+			}
+			outNames = append(outNames, outName)
+		}
+		return inNames, outNames, nil
+	}
+
 	inInfo, err := os.Stat(in)
 	if err != nil {
 		return inNames, outNames, errors.New(fmt.Sprintf("unknown file, %v\n", err))
@@ -126,3 +223,26 @@ func inToOutFilenames(in, inExt, out, outExt string) (inNames, outNames []string
 	// Everything else is forbidden.
 	return inNames, outNames, errors.New("in and out must be both files or directories")
 }
+
+// writeSNPs writes SNPs to a CSV file or to stdout if filename is empty.
+// If there are annotations, the SNPs are written as records with
+// the columns Pos, Ref, Alt, Name and Comment.
+func writeSNPs(snps snp.SNPs, annotations snp.Annotations, filename string) error {
+	if len(annotations) == 0 {
+		if filename != "" {
+			return snps.WriteCSV(filename)
+		}
+		for _, s := range snps {
+			os.Stdout.WriteString(s.String())
+		}
+		return nil
+	}
+	records := annotations.Records(snps)
+	if filename != "" {
+		return records.WriteCSV(filename)
+	}
+	for _, r := range records {
+		os.Stdout.WriteString(r.String())
+	}
+	return nil
+}
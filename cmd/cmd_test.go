@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+func TestParameterToDBSources(t *testing.T) {
+	tests := []struct {
+		param   string
+		sources []snp.DBSource
+		err     bool
+	}{
+		{param: "snps.csv", sources: []snp.DBSource{{Name: "snps.csv", Format: snp.FormatISOGG, Filename: "snps.csv"}}},
+		{param: "isogg:snps.csv,csv:private.csv,yfull:ytree.json", sources: []snp.DBSource{
+			{Name: "snps.csv", Format: snp.FormatISOGG, Filename: "snps.csv"},
+			{Name: "private.csv", Format: snp.FormatCSV, Filename: "private.csv"},
+			{Name: "ytree.json", Format: snp.FormatYFull, Filename: "ytree.json"},
+		}},
+		{param: "YFull:ytree.json", sources: []snp.DBSource{{Name: "ytree.json", Format: snp.FormatYFull, Filename: "ytree.json"}}},
+		{param: "yful:ytree.json", err: true},
+		{param: "csv:", err: true},
+	}
+	for _, test := range tests {
+		sources, err := parameterToDBSources(test.param)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: missing error", test.param)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.param, err)
+			continue
+		}
+		if len(sources) != len(test.sources) {
+			t.Errorf("%s: got %v, want %v", test.param, sources, test.sources)
+			continue
+		}
+		for i := range sources {
+			if sources[i] != test.sources[i] {
+				t.Errorf("%s: got %v, want %v", test.param, sources[i], test.sources[i])
+			}
+		}
+	}
+}
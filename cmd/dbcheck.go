@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// DBCheck merges several SNP data base sources and reports
+// the conflicts between them.
+// cmdLine: command line parameters without the subcommand.
+func DBCheck(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		isoggdb = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db      = flags.String("db", "", "List of SNP data base sources separated by commas, ordered by priority.")
+		out     = flags.String("out", "", "Output file for the conflict report.")
+		nocache = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+	)
+	flags.Parse(cmdLine)
+
+	if *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db not specified.\n")
+		os.Exit(1)
+	}
+
+	snpDB, conflicts, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := bufio.NewWriter(outfile)
+
+	// Conflicts, sorted by position and name.
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i].Record, conflicts[j].Record
+		if a.Key.Pos != b.Key.Pos {
+			return a.Key.Pos < b.Key.Pos
+		}
+		return a.Name < b.Name
+	})
+	kinds := make(map[string]int)
+	for _, c := range conflicts {
+		w.WriteString(c.String())
+		kinds[c.Kind]++
+	}
+
+	// Summary.
+	sources := make(map[string]int)
+	fields := make(map[string]int)
+	for _, rec := range snpDB.Records() {
+		sources[rec.Source]++
+		for _, source := range rec.FieldSources {
+			fields[source]++
+			// Sources without positions only supply fields.
+			sources[source] += 0
+		}
+	}
+	w.WriteString("\r\n")
+	for _, source := range sortedKeys(sources) {
+		fmt.Fprintf(w, "Source %s: %d records, %d fields supplied to other records\r\n", source, sources[source], fields[source])
+	}
+	for _, kind := range sortedKeys(kinds) {
+		fmt.Fprintf(w, "Conflicts %s: %d\r\n", kind, kinds[kind])
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing conflict report")
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// DBDiff compares two versions of an SNP data base and reports
+// added, removed, renamed and moved SNPs. Optionally lookup
+// is run again on all kits that are affected by the changes.
+// cmdLine: command line parameters without the subcommand.
+func DBDiff(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		oldParam = flags.String("old", "", "Old SNP data base sources separated by commas, for example isogg:snps_hg38.csv.")
+		newParam = flags.String("new", "", "New SNP data base sources separated by commas.")
+		out      = flags.String("out", "", "Output file for the list of changes in CSV format.")
+		kits     = flags.String("kits", "", "List of SNP CSV files or directories for which lookup should be run again.")
+		kitsout  = flags.String("kitsout", "", "Output directory for the lookup results of affected kits.")
+		nocache  = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+	)
+	flags.Parse(cmdLine)
+
+	if *oldParam == "" || *newParam == "" {
+		fmt.Printf("Parameters old and new must be specified.\n")
+		os.Exit(1)
+	}
+	if *kits != "" && *kitsout == "" {
+		fmt.Printf("Parameter kitsout is required for kits.\n")
+		os.Exit(1)
+	}
+
+	oldDB, _, err := dbFromParameters("", *oldParam, *nocache)
+	checkFatal(err, "Error reading old SNP data base")
+	newDB, _, err := dbFromParameters("", *newParam, *nocache)
+	checkFatal(err, "Error reading new SNP data base")
+
+	diff := snp.DiffDB(oldDB, newDB)
+
+	// Write changes.
+	// Columns: change, name, pos, ref, alt, old name, old pos, old ref, old alt.
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := csv.NewWriter(outfile)
+	w.UseCRLF = true
+	for _, r := range diff.Added {
+		w.Write(append([]string{"added"}, dbRecordFields(r)...))
+	}
+	for _, r := range diff.Removed {
+		w.Write(append([]string{"removed", "", "", "", ""}, dbRecordFields(r)...))
+	}
+	for _, c := range diff.Renamed {
+		w.Write(append(append([]string{"renamed"}, dbRecordFields(c.New)...), dbRecordFields(c.Old)...))
+	}
+	for _, c := range diff.Moved {
+		w.Write(append(append([]string{"moved"}, dbRecordFields(c.New)...), dbRecordFields(c.Old)...))
+	}
+	w.Flush()
+	checkFatal(w.Error(), "Error writing list of changes")
+	if *out != "" {
+		fmt.Printf("%d added, %d removed, %d renamed, %d moved.\n",
+			len(diff.Added), len(diff.Removed), len(diff.Renamed), len(diff.Moved))
+	}
+
+	// Run lookup again for affected kits.
+	if *kits == "" {
+		return
+	}
+	filenames, err := parameterToFilenames(*kits, ".csv")
+	checkFatal(err, "Error parsing parameter kits")
+	// Kits are written to kitsout under their base names,
+	// which must be unique.
+	outNames := make(map[string]string)
+	for _, filename := range filenames {
+		base := filepath.Base(filename)
+		if previous, exists := outNames[base]; exists && previous != filename {
+			fmt.Printf("Kits %s and %s have the same name.\n", previous, filename)
+			os.Exit(1)
+		}
+		outNames[base] = filename
+	}
+	err = os.MkdirAll(*kitsout, 0755)
+	checkFatal(err, "Error creating output directory")
+	affected := diff.AffectedPositions()
+	for _, filename := range filenames {
+		snps, err := snp.ReadCSV(filename)
+		checkFatal(err, "Error reading input CSV file")
+		isAffected := false
+		for _, s := range snps {
+			if affected[s.Pos] {
+				isAffected = true
+				break
+			}
+		}
+		if !isAffected {
+			continue
+		}
+		records, _ := lookupSNPs(snps, newDB)
+		records.Sort()
+		outName := filepath.Join(*kitsout, filepath.Base(filename))
+		err = records.WriteCSV(outName)
+		checkFatal(err, "Error writing to CSV file")
+		fmt.Printf("Updated %s.\n", outName)
+	}
+}
+
+// dbRecordFields returns name, position, ancestral and derived
+// allele of a data base record.
+func dbRecordFields(r *snp.DBRecord) []string {
+	return []string{r.Name, strconv.Itoa(r.Key.Pos), r.Key.Ref, r.Key.Alt}
+}
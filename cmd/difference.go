@@ -37,23 +37,18 @@ func Difference(cmdLine []string) {
 	checkFatal(err, "Error parsing parameter bin")
 
 	// Calculate unions of a and b and afterwards the difference a\b.
-	var a snp.SNPs = make(map[snp.SNP]bool)
-	err = unionWithFiles(a, aFilenames)
+	var a snp.SNPs
+	annotations := make(snp.Annotations)
+	err = unionWithFiles(&a, annotations, aFilenames)
 	checkFatal(err, "Error calculating the union of files for parameter ain")
 
-	var b snp.SNPs = make(map[snp.SNP]bool)
-	err = unionWithFiles(b, bFilenames)
+	var b snp.SNPs
+	err = unionWithFiles(&b, annotations, bFilenames)
 	checkFatal(err, "Error calculating the union of files for parameter bin")
 
 	a.Difference(b)
 
 	// Output SNPs.
-	if *out != "" {
-		err := a.WriteCSV(*out)
-		checkFatal(err, "Error writing SNPs to CSV output file")
-	} else {
-		for snp, _ := range a {
-			fmt.Print(snp.String())
-		}
-	}
+	err = writeSNPs(a, annotations, *out)
+	checkFatal(err, "Error writing SNPs to CSV output file")
 }
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Distance calculates pairwise distances between kits.
+// For distances, BED files are required for all kits or for none,
+// because the distance of kits without BED files is a number of SNPs
+// and not a number of SNPs per callable length.
+// cmdLine: command line parameters without the subcommand.
+func Distance(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in     = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		beds   = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		out    = flags.String("out", "", "Output file.")
+		metric = flags.String("metric", "distance", "Matrix values: distance (private SNPs per callable Mbp), shared, private or jaccard.")
+		format = flags.String("format", "csv", "Output format: csv (matrix), phylip (distance matrix) or pairs (all values for each pair of kits).")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in for input files not specified.\n")
+		os.Exit(1)
+	}
+	switch *metric {
+	case "distance", "shared", "private", "jaccard":
+	default:
+		fmt.Printf("Parameter metric must be distance, shared, private or jaccard.\n")
+		os.Exit(1)
+	}
+	switch *format {
+	case "csv", "pairs":
+	case "phylip":
+		if *metric != "distance" && *metric != "jaccard" {
+			fmt.Printf("Format phylip requires metric distance or jaccard.\n")
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Parameter format must be csv, phylip or pairs.\n")
+		os.Exit(1)
+	}
+
+	kits, err := readKits(*in, *beds)
+	checkFatal(err, "Error reading kits")
+	if *metric == "distance" || *format == "pairs" {
+		var withoutBED []string
+		for _, k := range kits {
+			if k.callable == nil {
+				withoutBED = append(withoutBED, k.name)
+			}
+		}
+		if len(withoutBED) > 0 && len(withoutBED) < len(kits) {
+			fmt.Printf("Kits without BED files: %s. BED files are required for all kits or for none.\n", strings.Join(withoutBED, ", "))
+			os.Exit(1)
+		}
+	}
+
+	// Compare all pairs of kits.
+	n := len(kits)
+	distances := make([][]snp.KitDistance, n)
+	for i := range distances {
+		distances[i] = make([]snp.KitDistance, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			d := snp.CompareKits(kits[i].snps, kits[j].snps, kits[i].callable, kits[j].callable)
+			distances[i][j] = d
+			d.PrivateA, d.PrivateB = d.PrivateB, d.PrivateA
+			distances[j][i] = d
+		}
+	}
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := bufio.NewWriter(outfile)
+
+	// value returns the selected metric for a pair of kits.
+	value := func(d snp.KitDistance) string {
+		switch *metric {
+		case "shared":
+			return strconv.Itoa(d.Shared)
+		case "private":
+			return strconv.Itoa(d.PrivateA)
+		case "jaccard":
+			if *format == "phylip" {
+				return strconv.FormatFloat(1-d.Jaccard, 'f', 6, 64)
+			}
+			return strconv.FormatFloat(d.Jaccard, 'f', 6, 64)
+		}
+		return formatDistance(d.Distance)
+	}
+
+	// Kits without common callable regions have no distance,
+	// which can not be written in PHYLIP format.
+	if *format == "phylip" && *metric == "distance" {
+		for i := 0; i < n; i++ {
+			for j := i; j < n; j++ {
+				switch {
+				case !math.IsNaN(distances[i][j].Distance):
+				case i == j:
+					fmt.Printf("Kit %s has no callable regions.\n", kits[i].name)
+					os.Exit(1)
+				default:
+					fmt.Printf("Kits %s and %s have no common callable regions.\n", kits[i].name, kits[j].name)
+					os.Exit(1)
+				}
+			}
+		}
+	}
+
+	switch *format {
+	case "csv":
+		// The cell in row i and column j belongs to kit i compared with kit j.
+		w.WriteString("Kit")
+		for _, k := range kits {
+			w.WriteString("," + k.name)
+		}
+		w.WriteString("\r\n")
+		for i, k := range kits {
+			w.WriteString(k.name)
+			for j := range kits {
+				w.WriteString("," + value(distances[i][j]))
+			}
+			w.WriteString("\r\n")
+		}
+	case "phylip":
+		fmt.Fprintf(w, "%5d\n", n)
+		for i, k := range kits {
+			fmt.Fprintf(w, "%-10.10s", k.name)
+			for j := range kits {
+				w.WriteString(" " + value(distances[i][j]))
+			}
+			w.WriteString("\n")
+		}
+	case "pairs":
+		w.WriteString("KitA,KitB,Shared,PrivateA,PrivateB,Jaccard,Callable,Distance\r\n")
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				d := distances[i][j]
+				fmt.Fprintf(w, "%s,%s,%d,%d,%d,%.6f,%d,%s\r\n",
+					kits[i].name, kits[j].name, d.Shared, d.PrivateA, d.PrivateB, d.Jaccard, d.Callable, formatDistance(d.Distance))
+			}
+		}
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing distances")
+}
+
+// formatDistance formats a distance with six decimal places.
+// A missing distance (NaN) is written as an empty string.
+func formatDistance(d float64) string {
+	if math.IsNaN(d) {
+		return ""
+	}
+	return strconv.FormatFloat(d, 'f', 6, 64)
+}
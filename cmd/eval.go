@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Eval evaluates a set expression over SNP CSV files, for example
+// (kitA | kitB) & kitC - excludes.csv.
+// Operators are | (union), & (intersection) and - (difference).
+// & binds stronger than | and -. Operands are files or directories.
+// The extension .csv may be omitted. A directory stands for the union
+// of all CSV files it contains. Operands without CSV files are errors. Kits of the project workspace are
+// denoted by their IDs prefixed by @. The difference operator must be
+// separated from the preceding operand by white space, because
+// filenames may contain hyphens.
+// cmdLine: command line parameters without the subcommand.
+func Eval(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		expr = flags.String("expr", "", "Set expression, for example \"(kitA | kitB) & kitC - excludes.csv\".")
+		dir  = flags.String("dir", "", "Directory for operands with relative names.")
+		out  = flags.String("out", "", "Output file in CSV format.")
+	)
+	flags.Parse(cmdLine)
+
+	if *expr == "" {
+		fmt.Printf("Parameter expr not specified.\n")
+		os.Exit(1)
+	}
+
+	tree, err := parseSetExpr(*expr)
+	checkFatal(err, "Error parsing expression")
+	annotations := make(snp.Annotations)
+	snps, err := tree.eval(*dir, annotations)
+	checkFatal(err, "Error evaluating expression")
+
+	// Output SNPs.
+	err = writeSNPs(snps, annotations, *out)
+	checkFatal(err, "Error writing SNPs to CSV output file")
+}
+
+// setExpr is a node in the expression tree of a set expression.
+type setExpr interface {
+	// eval evaluates the expression. Relative filenames
+	// are interpreted relative to dir. The names and comments
+	// of all operands are merged into annotations.
+	eval(dir string, annotations snp.Annotations) (snp.SNPs, error)
+}
+
+// setOperand is a file or directory in a set expression.
+type setOperand struct {
+	name string
+}
+
+// setOperation is a binary set operation.
+type setOperation struct {
+	op          byte
+	left, right setExpr
+}
+
+func (o *setOperand) eval(dir string, annotations snp.Annotations) (snp.SNPs, error) {
+	name := o.name
+	if dir != "" && !filepath.IsAbs(name) && !strings.HasPrefix(name, "@") {
+		name = filepath.Join(dir, name)
+	}
+	if _, err := os.Stat(name); err != nil {
+		if _, errExt := os.Stat(name + ".csv"); errExt == nil {
+			name += ".csv"
+		}
+	}
+	filenames, err := parameterToFilenames(name, ".csv")
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, errors.New(fmt.Sprintf("no CSV files found for %s", o.name))
+	}
+	var snps snp.SNPs
+	err = unionWithFiles(&snps, annotations, filenames)
+	return snps, err
+}
+
+func (o *setOperation) eval(dir string, annotations snp.Annotations) (snp.SNPs, error) {
+	a, err := o.left.eval(dir, annotations)
+	if err != nil {
+		return nil, err
+	}
+	b, err := o.right.eval(dir, annotations)
+	if err != nil {
+		return nil, err
+	}
+	switch o.op {
+	case '|':
+		a.Union(b)
+	case '&':
+		a.Intersection(b)
+	case '-':
+		a.Difference(b)
+	}
+	return a, nil
+}
+
+// parseSetExpr parses a set expression into an expression tree.
+//
+//	expr   = term { ("|" | "-") term }
+//	term   = factor { "&" factor }
+//	factor = "(" expr ")" | operand
+func parseSetExpr(expr string) (setExpr, error) {
+	tokens, err := tokenizeSetExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &setExprParser{tokens: tokens}
+	tree, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.New(fmt.Sprintf("unexpected %q", p.tokens[p.pos]))
+	}
+	return tree, nil
+}
+
+// tokenizeSetExpr splits a set expression into operators,
+// parentheses and operands.
+func tokenizeSetExpr(expr string) (tokens []string, err error) {
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("()|&-", r):
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()|&", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	return tokens, nil
+}
+
+// setExprParser is a recursive descent parser for set expressions.
+type setExprParser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the current token or an empty string
+// at the end of the expression.
+func (p *setExprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *setExprParser) expr() (setExpr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t == "|" || t == "-"; t = p.peek() {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &setOperation{op: t[0], left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *setExprParser) term() (setExpr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&" {
+		p.pos++
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = &setOperation{op: '&', left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *setExprParser) factor() (setExpr, error) {
+	t := p.peek()
+	switch t {
+	case "":
+		return nil, errors.New("unexpected end of expression")
+	case "(":
+		p.pos++
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return e, nil
+	case ")", "|", "&", "-":
+		return nil, errors.New(fmt.Sprintf("unexpected %q", t))
+	}
+	p.pos++
+	return &setOperand{name: t}, nil
+}
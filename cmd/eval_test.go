@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// formatSetExpr returns an expression tree with parentheses
+// around each operation.
+func formatSetExpr(e setExpr) string {
+	switch e := e.(type) {
+	case *setOperand:
+		return e.name
+	case *setOperation:
+		return "(" + formatSetExpr(e.left) + " " + string(e.op) + " " + formatSetExpr(e.right) + ")"
+	}
+	return "?"
+}
+
+func TestParseSetExpr(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"a", "a"},
+		{"a | b", "(a | b)"},
+		{"a | b & c", "(a | (b & c))"},
+		{"a & b | c", "((a & b) | c)"},
+		{"a - b & c", "(a - (b & c))"},
+		{"a | b - c", "((a | b) - c)"},
+		{"a - b | c", "((a - b) | c)"},
+		{"(a | b) & c", "((a | b) & c)"},
+		{"a & (b - c)", "(a & (b - c))"},
+		{"a&b&c", "((a & b) & c)"},
+		{"kit-1 - kit-2", "(kit-1 - kit-2)"},
+		{"dir/a.csv|dir/b.csv", "(dir/a.csv | dir/b.csv)"},
+		{"@12345 | @", "(@12345 | @)"},
+	}
+	for _, test := range tests {
+		e, err := parseSetExpr(test.expr)
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		if got := formatSetExpr(e); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestParseSetExprErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"a |",
+		"| a",
+		"(a | b",
+		"a | b)",
+		"a b",
+		"a & & b",
+		"()",
+	}
+	for _, expr := range tests {
+		if e, err := parseSetExpr(expr); err == nil {
+			t.Errorf("%q: missing error, got %s", expr, formatSetExpr(e))
+		}
+	}
+}
+
+func TestEvalSetExpr(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csv":        "100,C,T\r\n200,A,G\r\n300,G,A\r\n",
+		"b.csv":        "200,A,G\r\n400,C,A\r\n",
+		"excludes.csv": "300,G,A\r\n",
+		"notes.txt":    "300,G,A\r\n",
+		"kits/c.csv":   "400,C,A\r\n",
+		"kits/d.csv":   "500,T,C\r\n",
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "kits"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		expr string
+		want string
+		err  bool
+	}{
+		{expr: "a | b", want: "100 200 300 400"},
+		{expr: "a & b", want: "200"},
+		{expr: "a.csv - excludes.csv", want: "100 200"},
+		{expr: "a | b & kits", want: "100 200 300 400"},
+		{expr: "(a | b) & kits", want: "400"},
+		{expr: "kits - b", want: "500"},
+		{expr: "a - typo.csv", err: true},
+		{expr: "a - notes.txt", err: true},
+		{expr: "a - empty", err: true},
+	}
+	for _, test := range tests {
+		e, err := parseSetExpr(test.expr)
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		snps, err := e.eval(dir, make(snp.Annotations))
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: missing error", test.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		var got []string
+		for _, s := range snps {
+			got = append(got, strconv.Itoa(s.Pos))
+		}
+		if strings.Join(got, " ") != test.want {
+			t.Errorf("%s: got %v, want %s", test.expr, got, test.want)
+		}
+	}
+}
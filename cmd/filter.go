@@ -44,7 +44,7 @@ func Filter(cmdLine []string) {
 	checkFatal(err, "Error converting filenames from parameter in to out")
 
 	for i, _ := range inFiles {
-		snps, err := snp.ReadCSV(inFiles[i])
+		snps, annotations, err := snp.ReadAnnotatedCSV(inFiles[i])
 		checkFatal(err, "Error reading input CSV file")
 
 		// Peform filter operations.
@@ -52,21 +52,13 @@ func Filter(cmdLine []string) {
 			snps.Difference(ex)
 		}
 		if *bed != "" {
-			for s, _ := range snps {
-				if bedRegs.Includes(s.Pos) == false {
-					delete(snps, s)
-				}
-			}
+			snps.Filter(func(s snp.SNP) bool {
+				return bedRegs.Includes(s.Pos)
+			})
 		}
 
 		// Write to file or stdout.
-		if outFiles[i] != "" {
-			err := snps.WriteCSV(outFiles[i])
-			checkFatal(err, "Error writing to CSV file")
-		} else {
-			for snp := range snps {
-				os.Stdout.WriteString(snp.String())
-			}
-		}
+		err = writeSNPs(snps, annotations, outFiles[i])
+		checkFatal(err, "Error writing to CSV file")
 	}
 }
@@ -18,6 +18,9 @@ func FilterFTDNA(cmdLine []string) {
 		mutationsonly = flags.Bool("mutationsonly", true, "If mutationsonly=true only mutations are reported.")
 		novelsonly    = flags.Bool("novelsonly", false, "If novelsonly=true only novel variants are reported.")
 		isoggdb       = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db            = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache       = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+		polarize      = flags.Bool("polarize", false, "If polarize=true only derived SNPs according to the SNP data base and novel mutations are reported. Implies mutationsonly=false.")
 	)
 	flags.Parse(cmdLine)
 
@@ -31,18 +34,24 @@ func FilterFTDNA(cmdLine []string) {
 		os.Exit(1)
 	}
 
-	var snpDB *snp.DB
-	if *isoggdb != "" {
-		snpDB = snp.NewDB()
-		err := snpDB.ReadISOGGcsv(*isoggdb)
-		checkFatal(err, "Error reading SNP definitions from ISOGG CSV file")
+	if *polarize && *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db is required for polarize.\n")
+		os.Exit(1)
 	}
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
 
 	inNames, outNames, err := inToOutFilenames(*in, ".csv", *out, ".csv")
 	checkFatal(err, "Error converting filenames from parameter in to out")
 	for i, _ := range inNames {
-		recs, err := snp.ReadFTDNAcsv(inNames[i], *mutationsonly, *novelsonly, snpDB)
+		recs, err := snp.ReadFTDNAcsv(inNames[i], *mutationsonly && !*polarize, *novelsonly, snpDB)
 		checkFatal(err, "Error reading FTDNA CSV file")
+		if *polarize {
+			// Derived SNPs and novel mutations.
+			derived, _, novels := snpDB.PolarizeCSV(recs)
+			recs = append(derived, novels...)
+		}
+		recs.Sort()
 		if outNames[i] != "" {
 			// Write to file.
 			err := recs.WriteCSV(outNames[i])
@@ -20,6 +20,10 @@ func FilterVCF(cmdLine []string) {
 		mutationsonly = flags.Bool("mutationsonly", true, "If mutationsonly=true only mutations are reported.")
 		reads         = flags.Int("reads", 3, "Minimum of allele reads for a valid result.")
 		ratio         = flags.Int("ratio", 3, "Minimum ratio of allele value to alternative results.")
+		polarize      = flags.Bool("polarize", false, "If polarize=true only derived SNPs according to the SNP data base and novel mutations are reported. Implies mutationsonly=false.")
+		isoggdb       = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db            = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache       = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
 	)
 	flags.Parse(cmdLine)
 
@@ -33,18 +37,31 @@ func FilterVCF(cmdLine []string) {
 		os.Exit(1)
 	}
 
+	if *polarize && *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db is required for polarize.\n")
+		os.Exit(1)
+	}
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
+
 	inNames, outNames, err := inToOutFilenames(*in, ".vcf", *out, ".csv")
 	checkFatal(err, "Error converting filenames from parameter in to out")
 	for i, _ := range inNames {
-		snps, err := snp.ReadVCF(inNames[i], *quality, *mutationsonly, *reads, *ratio)
+		snps, err := snp.ReadVCF(inNames[i], *quality, *mutationsonly && !*polarize, *reads, *ratio)
 		checkFatal(err, "Error reading VCF file")
+		if *polarize {
+			// Derived SNPs and novel mutations.
+			derived, _, novels := snpDB.PolarizeSNPs(snps)
+			derived.Union(novels)
+			snps = derived
+		}
 		if outNames[i] != "" {
 			// Write to file.
 			err := snps.WriteCSV(outNames[i])
 			checkFatal(err, "Error writing to CSV file")
 		} else {
 			// Write to stdout.
-			for snp := range snps {
+			for _, snp := range snps {
 				os.Stdout.WriteString(snp.String())
 			}
 		}
@@ -47,7 +47,7 @@ func FilterYFull(cmdLine []string) {
 			checkFatal(err, "Error writing to CSV file")
 		} else {
 			// Write to stdout.
-			for snp := range snps {
+			for _, snp := range snps {
 				os.Stdout.WriteString(snp.String())
 			}
 		}
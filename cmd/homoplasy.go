@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+	"github.com/yogischogi/phylosnip/tree"
+)
+
+// Homoplasy finds SNPs that are incompatible with a tree because
+// of parallel mutations or reversions. The tree is built from the
+// kits or read from a file.
+// cmdLine: command line parameters without the subcommand.
+func Homoplasy(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in       = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		beds     = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		treeFile = flags.String("tree", "", "Input tree file with the kits as tips. If it is not specified, the tree is built from the kits.")
+		treeFmt  = flags.String("treeformat", "auto", "Format of the tree file: newick (also NEXUS), yfull (JSON), yfullcsv, ftdna (JSON) or auto.")
+		out      = flags.String("out", "", "Output file for the report.")
+		exclude  = flags.String("exclude", "", "Output file for the incompatible SNPs in CSV format, usable with filter -exclude.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in for input files not specified.\n")
+		os.Exit(1)
+	}
+
+	kits, err := readKits(*in, *beds)
+	checkFatal(err, "Error reading kits")
+	names := make([]string, len(kits))
+	sets := make([]snp.SNPs, len(kits))
+	callable := make([]snp.BEDRegions, len(kits))
+	annotations := make(snp.Annotations)
+	for i, k := range kits {
+		names[i], sets[i], callable[i] = k.name, k.snps, k.callable
+		annotations.Merge(k.annotations)
+	}
+	m := snp.NewMatrix(names, sets, callable)
+
+	var t *tree.Tree
+	if *treeFile != "" {
+		t, err = readTree(*treeFile, *treeFmt)
+		checkFatal(err, "Error reading tree file")
+	} else {
+		t = tree.Build(m, annotations)
+	}
+	homoplasies := t.Homoplasies(m)
+
+	// Write report.
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := bufio.NewWriter(outfile)
+	for _, h := range homoplasies {
+		w.WriteString(strings.TrimSuffix(h.SNP.String(), "\r\n"))
+		if name := annotations[h.SNP].Name; name != "" {
+			w.WriteString(" " + name)
+		}
+		fmt.Fprintf(w, ": %s on %s", h.Kind, nodeLabels(h.Mutations))
+		if len(h.Reversions) > 0 {
+			fmt.Fprintf(w, ", reverted on %s", nodeLabels(h.Reversions))
+		}
+		w.WriteString("; derived in " + strings.Join(h.Derived, ", ") + "\r\n")
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing report")
+
+	// Write exclude list.
+	if *exclude != "" {
+		snps := make([]snp.SNP, len(homoplasies))
+		for i, h := range homoplasies {
+			snps[i] = h.SNP
+		}
+		err = snp.NewSNPs(snps).WriteCSV(*exclude)
+		checkFatal(err, "Error writing exclude file")
+	}
+}
+
+// nodeLabels returns the labels of nodes separated by commas.
+func nodeLabels(nodes []*tree.Node) string {
+	labels := make([]string, len(nodes))
+	for i, n := range nodes {
+		labels[i] = n.Label
+	}
+	return strings.Join(labels, ", ")
+}
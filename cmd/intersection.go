@@ -34,31 +34,27 @@ func Intersection(cmdLine []string) {
 	}
 
 	// Calculate intersection.
-	snps, err := snp.ReadCSV(filenames[0])
+	snps, annotations, err := snp.ReadAnnotatedCSV(filenames[0])
 	checkFatal(err, "Error reading CSV file")
-	err = intersectionWithFiles(snps, filenames[1:])
+	err = intersectionWithFiles(&snps, annotations, filenames[1:])
 	checkFatal(err, "Error calculating the intersection of files")
 
 	// Output SNPs.
-	if *out != "" {
-		err := snps.WriteCSV(*out)
-		checkFatal(err, "Error writing SNPs to CSV output file")
-	} else {
-		for snp, _ := range snps {
-			fmt.Print(snp.String())
-		}
-	}
+	err = writeSNPs(snps, annotations, *out)
+	checkFatal(err, "Error writing SNPs to CSV output file")
 }
 
 // intersectionWithFiles calculates the set intersection of snps with the SNPs
-// contained in the files specified by filenames.
-func intersectionWithFiles(snps snp.SNPs, filenames []string) error {
+// contained in the files specified by filenames. The names and comments
+// of the SNPs are merged into annotations.
+func intersectionWithFiles(snps *snp.SNPs, annotations snp.Annotations, filenames []string) error {
 	for _, filename := range filenames {
-		s, err := snp.ReadCSV(filename)
+		s, a, err := snp.ReadAnnotatedCSV(filename)
 		if err != nil {
 			return errors.New(fmt.Sprintf("reading CSV file, %v", err))
 		}
 		snps.Intersection(s)
+		annotations.Merge(a)
 	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// kit contains the SNPs of a single test result.
+type kit struct {
+	// name is the filename without directory and extension.
+	name string
+	snps snp.SNPs
+	// annotations contains the names and comments of the SNPs.
+	annotations snp.Annotations
+	// callable are the callable regions of the kit or
+	// nil if they are unknown.
+	callable snp.BEDRegions
+}
+
+// readKits reads kits from the SNP CSV files specified by the
+// parameter in, like parameterToFilenames does.
+// If beds is not empty, it is a directory containing a BED file with
+// the callable regions for each kit. The BED file has the same name as
+// the kit file with the extension .bed. If beds is empty, kits of a
+// workspace use the BED files of the workspace. Kits without a BED
+// file get nil callable regions.
+func readKits(in, beds string) ([]kit, error) {
+	filenames, err := parameterToFilenames(in, ".csv")
+	if err != nil {
+		return nil, err
+	}
+	kits := make([]kit, 0, len(filenames))
+	for _, filename := range filenames {
+		var k kit
+		k.name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		k.snps, k.annotations, err = snp.ReadAnnotatedCSV(filename)
+		if err != nil {
+			return kits, errors.New(fmt.Sprintf("reading CSV file %s, %v", filename, err))
+		}
+		bedName := workspaceBED(filename)
+		if beds != "" {
+			bedName = filepath.Join(beds, k.name+".bed")
+		}
+		if bedName != "" {
+			if _, err := os.Stat(bedName); err == nil {
+				k.callable, err = snp.ReadBED(bedName)
+				if err != nil {
+					return kits, errors.New(fmt.Sprintf("reading BED file %s, %v", bedName, err))
+				}
+			}
+		}
+		kits = append(kits, k)
+	}
+	return kits, nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Liftover converts SNP and BED files between genome builds
+// using a UCSC chain file.
+// cmdLine: command line parameters without the subcommand.
+func Liftover(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in       = flags.String("in", "", "Input file or directory.")
+		out      = flags.String("out", "", "Output file or directory.")
+		chain    = flags.String("chain", "", "UCSC chain file, for example hg19ToHg38.over.chain.")
+		filetype = flags.String("type", "", "Type of input files: snp (Pos,Ref,Alt), csv (Pos,Ref,Alt,Name,Comment) or bed. Default is bed for .bed files and snp otherwise.")
+		report   = flags.String("report", "", "Output file for a report of unmapped and strand-flipped positions.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in not specified.\n")
+		os.Exit(1)
+	}
+	if *in == *out {
+		fmt.Printf("Parameter in and out may not be identical.\n")
+		os.Exit(1)
+	}
+	if *chain == "" {
+		fmt.Printf("Parameter chain not specified.\n")
+		os.Exit(1)
+	}
+	if *filetype == "" {
+		*filetype = "snp"
+		if strings.HasSuffix(strings.ToLower(*in), ".bed") {
+			*filetype = "bed"
+		}
+	}
+	ext := ".csv"
+	switch *filetype {
+	case "snp", "csv":
+	case "bed":
+		ext = ".bed"
+	default:
+		fmt.Printf("Parameter type must be snp, csv or bed.\n")
+		os.Exit(1)
+	}
+
+	c, err := snp.ReadChain(*chain)
+	checkFatal(err, "Error reading chain file")
+
+	inNames, outNames, err := inToOutFilenames(*in, ext, *out, ext)
+	checkFatal(err, "Error converting filenames from parameter in to out")
+
+	var rep *bufio.Writer
+	if *report != "" {
+		reportFile, err := os.Create(*report)
+		checkFatal(err, "Error creating report file")
+		defer reportFile.Close()
+		rep = bufio.NewWriter(reportFile)
+	}
+
+	for i, _ := range inNames {
+		var result, unmapped, flipped []string
+		switch *filetype {
+		case "snp":
+			snps, err := snp.ReadCSV(inNames[i])
+			checkFatal(err, "Error reading input CSV file")
+			lifted, u, f := c.LiftSNPs(snps)
+			if outNames[i] != "" {
+				err := lifted.WriteCSV(outNames[i])
+				checkFatal(err, "Error writing to CSV file")
+			}
+			for _, s := range lifted {
+				result = append(result, s.String())
+			}
+			for _, s := range u {
+				unmapped = append(unmapped, s.String())
+			}
+			for _, s := range f {
+				flipped = append(flipped, s.String())
+			}
+		case "csv":
+			recs, err := snp.ReadCSVRecords(inNames[i])
+			checkFatal(err, "Error reading input CSV file")
+			lifted, u, f := c.LiftCSV(recs)
+			lifted.Sort()
+			u.Sort()
+			f.Sort()
+			if outNames[i] != "" {
+				err := lifted.WriteCSV(outNames[i])
+				checkFatal(err, "Error writing to CSV file")
+			}
+			for _, r := range lifted {
+				result = append(result, r.String())
+			}
+			for _, r := range u {
+				unmapped = append(unmapped, r.String())
+			}
+			for _, r := range f {
+				flipped = append(flipped, r.String())
+			}
+		case "bed":
+			regions, err := snp.ReadBED(inNames[i])
+			checkFatal(err, "Error reading BED file")
+			lifted, u := c.LiftBED(regions)
+			if outNames[i] != "" {
+				err := lifted.WriteBED(outNames[i])
+				checkFatal(err, "Error writing to BED file")
+			}
+			for _, r := range lifted {
+				result = append(result, fmt.Sprintf("chrY\t%d\t%d\n", r.Start, r.End))
+			}
+			for _, r := range u {
+				unmapped = append(unmapped, fmt.Sprintf("chrY\t%d\t%d\n", r.Start, r.End))
+			}
+		}
+
+		// Write to stdout.
+		if outNames[i] == "" {
+			for _, line := range result {
+				os.Stdout.WriteString(line)
+			}
+		}
+
+		// Write report.
+		if rep != nil {
+			fmt.Fprintf(rep, "%s: %d converted, %d unmapped, %d strand-flipped\r\n", inNames[i], len(result), len(unmapped), len(flipped))
+			for _, line := range unmapped {
+				rep.WriteString("unmapped: " + line)
+			}
+			for _, line := range flipped {
+				rep.WriteString("flipped: " + line)
+			}
+		}
+	}
+	if rep != nil {
+		err := rep.Flush()
+		checkFatal(err, "Error writing report file")
+	}
+}
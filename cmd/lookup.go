@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/yogischogi/phylosnip/snp"
 )
@@ -17,6 +18,8 @@ func Lookup(cmdLine []string) {
 		in      = flags.String("in", "", "Input file in FTDNA CSV format.")
 		out     = flags.String("out", "", "Output file for list of SNPs in CSV format.")
 		isoggdb = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db      = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
 	)
 	flags.Parse(cmdLine)
 
@@ -25,17 +28,13 @@ func Lookup(cmdLine []string) {
 		os.Exit(1)
 	}
 
-	if *isoggdb == "" {
-		fmt.Printf("Parameter isoggdb not specified.\n")
+	if *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db not specified.\n")
 		os.Exit(1)
 	}
 
-	var snpDB *snp.DB
-	if *isoggdb != "" {
-		snpDB = snp.NewDB()
-		err := snpDB.ReadISOGGcsv(*isoggdb)
-		checkFatal(err, "Error reading SNP definitions from ISOGG CSV file")
-	}
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
 
 	inFiles, outFiles, err := inToOutFilenames(*in, ".csv", *out, ".csv")
 	checkFatal(err, "Error converting filenames from parameter in to out")
@@ -44,28 +43,16 @@ func Lookup(cmdLine []string) {
 		checkFatal(err, "Error reading input CSV file")
 
 		// Convert SNPs to CSV records with enhanced information.
-		var records snp.CSVRecords
-		for s, _ := range snps {
-			var rec snp.CSVRecord
-			dbRec, exists := snpDB.EntryByKey(s)
-			if exists {
-				rec = snp.CSVRecord{
-					Pos:     strconv.Itoa(dbRec.Key.Pos),
-					Ref:     dbRec.Key.Ref,
-					Alt:     dbRec.Key.Alt,
-					Name:    dbRec.Name,
-					Comment: dbRec.Comment,
-				}
-			} else {
-				rec = snp.CSVRecord{Pos: strconv.Itoa(s.Pos), Ref: s.Ref, Alt: s.Alt}
-			}
-			records = append(records, rec)
-		}
+		records, flipped := lookupSNPs(snps, snpDB)
+		records.Sort()
 
 		if outFiles[i] != "" {
 			// Write to file.
 			err := records.WriteCSV(outFiles[i])
 			checkFatal(err, "Error writing to CSV file")
+			if flipped > 0 {
+				fmt.Printf("%s: %d SNPs matched on the opposite strand.\n", inFiles[i], flipped)
+			}
 		} else {
 			// Write to stdout.
 			for _, r := range records {
@@ -74,3 +61,34 @@ func Lookup(cmdLine []string) {
 		}
 	}
 }
+
+// lookupSNPs converts SNPs to CSV records with information from the
+// SNP data base. SNPs whose alleles are given on the opposite strand
+// are matched too and keep their alleles. This is noted in the comment.
+// flipped is the number of these SNPs.
+func lookupSNPs(snps snp.SNPs, snpDB *snp.DB) (records snp.CSVRecords, flipped int) {
+	for _, s := range snps {
+		rec := snp.CSVRecord{Pos: strconv.Itoa(s.Pos), Ref: s.Ref, Alt: s.Alt}
+		m, exists := snpDB.MatchByKey(s)
+		if exists {
+			rec.Name = m.Record.Name
+			rec.Comment = m.Record.Description()
+			var notes []string
+			if m.Flipped {
+				notes = append(notes, "strand flipped")
+				flipped++
+			}
+			if m.Ambiguous {
+				notes = append(notes, "strand ambiguous")
+			}
+			if len(notes) > 0 {
+				if rec.Comment != "" {
+					notes = append([]string{rec.Comment}, notes...)
+				}
+				rec.Comment = strings.Join(notes, "; ")
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, flipped
+}
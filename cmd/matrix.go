@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Matrix writes a table with one row per SNP and one column per kit
+// that shows whether a kit is derived, ancestral or has no call.
+// cmdLine: command line parameters without the subcommand.
+func Matrix(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in          = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		beds        = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		out         = flags.String("out", "", "Output file.")
+		format      = flags.String("format", "csv", "Output format: csv or tsv.")
+		informative = flags.Bool("informative", false, "If informative=true only SNPs that are derived in at least two kits and ancestral in at least one kit are reported.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in for input files not specified.\n")
+		os.Exit(1)
+	}
+	sep := ","
+	switch *format {
+	case "csv":
+	case "tsv":
+		sep = "\t"
+	default:
+		fmt.Printf("Parameter format must be csv or tsv.\n")
+		os.Exit(1)
+	}
+
+	kits, err := readKits(*in, *beds)
+	checkFatal(err, "Error reading kits")
+	if len(kits) == 0 {
+		fmt.Printf("No files found for input parameter in.\n")
+		os.Exit(1)
+	}
+	names := make([]string, len(kits))
+	sets := make([]snp.SNPs, len(kits))
+	callable := make([]snp.BEDRegions, len(kits))
+	for i, k := range kits {
+		names[i], sets[i], callable[i] = k.name, k.snps, k.callable
+	}
+
+	m := snp.NewMatrix(names, sets, callable)
+	if *informative {
+		m = m.Informative()
+	}
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	err = m.Write(outfile, sep)
+	checkFatal(err, "Error writing matrix")
+}
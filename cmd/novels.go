@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Novels finds novel SNPs that are shared by several kits and could
+// define new branches. SNPs contained in the SNP data base are
+// excluded. For each group of kits sharing novel SNPs, the report
+// tells whether the other kits are ancestral or have no coverage
+// at the positions of the SNPs. Kits without BED files have no
+// coverage.
+// cmdLine: command line parameters without the subcommand.
+func Novels(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in         = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		beds       = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files. Kits without BED files are uncovered.")
+		out        = flags.String("out", "", "Output file.")
+		format     = flags.String("format", "text", "Output format: text or csv.")
+		min        = flags.Int("min", 2, "Minimum number of kits that must share a novel SNP.")
+		byPosition = flags.Bool("byposition", false, "If byposition=true SNPs at positions of data base entries are not novel, even if their alleles are different.")
+		isoggdb    = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db         = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache    = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in for input files not specified.\n")
+		os.Exit(1)
+	}
+	if *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db not specified.\n")
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "csv" {
+		fmt.Printf("Parameter format must be text or csv.\n")
+		os.Exit(1)
+	}
+
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
+	kits, err := readKits(*in, *beds)
+	checkFatal(err, "Error reading kits")
+	if len(kits) == 0 {
+		fmt.Printf("No files found for input parameter in.\n")
+		os.Exit(1)
+	}
+
+	// Find groups of novel SNPs.
+	names := make([]string, len(kits))
+	sets := make([]snp.SNPs, len(kits))
+	callable := make([]snp.BEDRegions, len(kits))
+	for i, k := range kits {
+		names[i], sets[i], callable[i] = k.name, snpDB.Novel(k.snps, *byPosition), k.callable
+		// Without a BED file nothing is known about ancestral SNPs.
+		if callable[i] == nil {
+			callable[i] = snp.BEDRegions{}
+		}
+	}
+	m := snp.NewMatrix(names, sets, callable)
+	groups := m.Groups(*min)
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	if *format == "csv" {
+		err = writeNovelsCSV(outfile, m, groups)
+	} else {
+		err = writeNovels(outfile, m, groups)
+	}
+	checkFatal(err, "Error writing novel SNPs")
+}
+
+// writeNovels writes a report with one section per group of kits.
+// For each kit outside of the group, the numbers of ancestral and
+// uncovered SNPs are listed.
+func writeNovels(outfile *os.File, m *snp.Matrix, groups []snp.SNPGroup) error {
+	w := bufio.NewWriter(outfile)
+	for i, g := range groups {
+		if i > 0 {
+			w.WriteString("\r\n")
+		}
+		kits := make([]string, len(g.Kits))
+		inGroup := make(map[int]bool)
+		for j, col := range g.Kits {
+			kits[j] = m.Kits[col]
+			inGroup[col] = true
+		}
+		snps := make([]string, len(g.Rows))
+		for j, row := range g.Rows {
+			s := m.SNPs[row]
+			snps[j] = strconv.Itoa(s.Pos) + "," + s.Ref + "," + s.Alt
+		}
+		fmt.Fprintf(w, "Kits: %s [%d SNPs]\r\n", strings.Join(kits, ", "), len(g.Rows))
+		w.WriteString("  SNPs: " + strings.Join(snps, "; ") + "\r\n")
+		for col, kit := range m.Kits {
+			if inGroup[col] {
+				continue
+			}
+			ancestral, uncovered := 0, 0
+			for _, row := range g.Rows {
+				switch m.Get(row, col) {
+				case snp.Ancestral:
+					ancestral++
+				case snp.Unknown:
+					uncovered++
+				}
+			}
+			fmt.Fprintf(w, "  %s: %d ancestral, %d uncovered\r\n", kit, ancestral, uncovered)
+		}
+	}
+	return w.Flush()
+}
+
+// writeNovelsCSV writes one line per SNP with the columns Pos, Ref, Alt,
+// derived kits, ancestral kits and uncovered kits. Kits are separated
+// by semicolons.
+func writeNovelsCSV(outfile *os.File, m *snp.Matrix, groups []snp.SNPGroup) error {
+	w := csv.NewWriter(outfile)
+	w.UseCRLF = true
+	for _, g := range groups {
+		for _, row := range g.Rows {
+			var derived, ancestral, uncovered []string
+			for col, kit := range m.Kits {
+				switch m.Get(row, col) {
+				case snp.Derived:
+					derived = append(derived, kit)
+				case snp.Ancestral:
+					ancestral = append(ancestral, kit)
+				default:
+					uncovered = append(uncovered, kit)
+				}
+			}
+			s := m.SNPs[row]
+			w.Write([]string{strconv.Itoa(s.Pos), s.Ref, s.Alt,
+				strings.Join(derived, ";"), strings.Join(ancestral, ";"), strings.Join(uncovered, ";")})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
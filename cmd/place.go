@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+	"github.com/yogischogi/phylosnip/tree"
+)
+
+// Place finds the position of kits in a haplogroup tree and reports
+// the supporting and conflicting SNPs along the path.
+// cmdLine: command line parameters without the subcommand.
+func Place(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in          = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		treeFile    = flags.String("tree", "", "Input tree file.")
+		treeFmt     = flags.String("treeformat", "auto", "Format of the tree file: newick (also NEXUS), yfull (JSON), yfullcsv, ftdna (JSON) or auto.")
+		isoggdb     = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format, used to find the positions of SNP names in the tree file.")
+		db          = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache     = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+		report      = flags.String("report", "", "Output file for SNP names of the tree file that could not be resolved or are ambiguous.")
+		beds        = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		negatives   = flags.String("negatives", "", "Directory with CSV files of ancestral SNPs, named like the kit files.")
+		allCallable = flags.Bool("allcallable", false, "If allcallable=true all SNPs that are not derived are considered ancestral, for example for whole genome tests without BED files.")
+		out         = flags.String("out", "", "Output file for the report.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" {
+		fmt.Printf("Parameter in not specified.\n")
+		os.Exit(1)
+	}
+	if *treeFile == "" {
+		fmt.Printf("Parameter tree not specified.\n")
+		os.Exit(1)
+	}
+
+	t, err := readTree(*treeFile, *treeFmt)
+	checkFatal(err, "Error reading tree file")
+	snpDB, err := linkTree(t, *isoggdb, *db, *nocache, *report)
+	checkFatal(err, "Error linking tree to SNP data base")
+	kits, err := readKits(*in, *beds)
+	checkFatal(err, "Error reading kits")
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := bufio.NewWriter(outfile)
+	for i, k := range kits {
+		var ancestral snp.SNPs
+		annotations := k.annotations
+		if *negatives != "" {
+			filename := filepath.Join(*negatives, k.name+".csv")
+			if _, err := os.Stat(filename); err == nil {
+				var a snp.Annotations
+				ancestral, a, err = snp.ReadAnnotatedCSV(filename)
+				checkFatal(err, "Error reading negatives file")
+				annotations = make(snp.Annotations)
+				annotations.Merge(k.annotations)
+				annotations.Merge(a)
+			}
+		}
+		callable := k.callable
+		if *allCallable {
+			callable = snp.BEDRegions{{Start: 0, End: math.MaxInt32}}
+		}
+		p := t.Place(tree.NewCalls(k.snps, ancestral, annotations, callable, snpDB))
+		if i > 0 {
+			w.WriteString("\r\n")
+		}
+		writePlacement(w, k.name, p)
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing report")
+}
+
+// writePlacement writes a human readable report of a placement.
+func writePlacement(w *bufio.Writer, kit string, p *tree.Placement) {
+	fmt.Fprintf(w, "Kit: %s\r\n", kit)
+	fmt.Fprintf(w, "Placement: %s\r\n", p.Node.Label)
+	downstream := "n/a"
+	if !math.IsNaN(p.Downstream) {
+		downstream = strconv.FormatFloat(p.Downstream, 'f', 2, 64)
+	}
+	fmt.Fprintf(w, "Confidence: %.2f (consistency %.2f, coverage %.2f, downstream %s)\r\n",
+		p.Confidence, p.Consistency, p.Coverage, downstream)
+	w.WriteString("Path:\r\n")
+	for _, bc := range p.Path {
+		fmt.Fprintf(w, "  %s: %d derived, %d ancestral, %d no call\r\n",
+			bc.Node.Label, len(bc.Derived), len(bc.Ancestral), len(bc.Unknown))
+		if len(bc.Derived) > 0 {
+			w.WriteString("    supporting: " + strings.Join(bc.Derived, ", ") + "\r\n")
+		}
+		if len(bc.Ancestral) > 0 {
+			w.WriteString("    conflicting: " + strings.Join(bc.Ancestral, ", ") + "\r\n")
+		}
+	}
+	if len(p.OffPath) > 0 {
+		w.WriteString("Derived SNPs outside of the path:\r\n")
+		for _, bc := range p.OffPath {
+			w.WriteString("  " + bc.Node.Label + ": " + strings.Join(bc.Derived, ", ") + "\r\n")
+		}
+	}
+}
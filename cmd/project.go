@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yogischogi/phylosnip/project"
+)
+
+// ProjectEnv is the environment variable that contains the directory
+// of the project workspace. If it is not set, the current directory is used.
+const ProjectEnv = "PHYLOSNIP_PROJECT"
+
+// Project manages the kits of a project workspace.
+// The first parameter is the action: init, add, remove or list.
+// cmdLine: command line parameters without the subcommand.
+func Project(cmdLine []string) {
+	if len(cmdLine) == 0 {
+		fmt.Printf("Action init, add, remove or list not specified.\n")
+		os.Exit(1)
+	}
+	action := cmdLine[0]
+
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		dir    = flags.String("dir", workspaceDir(), "Directory of the project workspace. Default is $"+ProjectEnv+" or the current directory.")
+		id     = flags.String("id", "", "Kit ID.")
+		in     = flags.String("in", "", "SNP CSV file of the kit to add.")
+		bed    = flags.String("bed", "", "BED file with the callable regions of the kit to add.")
+		vendor = flags.String("vendor", "", "Testing company of the kit, for example FTDNA or YFull.")
+		build  = flags.String("build", "", "Reference genome build of the kit, for example hg38.")
+		test   = flags.String("test", "", "Type of the test, for example Big Y-700.")
+	)
+	flags.Parse(cmdLine[1:])
+
+	if action == "init" {
+		_, err := project.Init(*dir)
+		checkFatal(err, "Error creating workspace")
+		return
+	}
+	w, err := project.Open(*dir)
+	checkFatal(err, "Error opening workspace")
+
+	switch action {
+	case "add":
+		if *id == "" {
+			fmt.Printf("Parameter id not specified.\n")
+			os.Exit(1)
+		}
+		if *in == "" {
+			fmt.Printf("Parameter in not specified.\n")
+			os.Exit(1)
+		}
+		kit := project.Kit{ID: *id, Vendor: *vendor, Build: *build, Test: *test}
+		err = w.Add(kit, *in, *bed)
+		checkFatal(err, "Error adding kit")
+		err = w.Save()
+		checkFatal(err, "Error saving manifest")
+	case "remove":
+		if *id == "" {
+			fmt.Printf("Parameter id not specified.\n")
+			os.Exit(1)
+		}
+		err = w.Remove(*id)
+		checkFatal(err, "Error removing kit")
+		err = w.Save()
+		checkFatal(err, "Error saving manifest")
+	case "list":
+		writeKitList(os.Stdout, w)
+	default:
+		fmt.Printf("Unknown action: %s\n", action)
+		os.Exit(1)
+	}
+}
+
+// writeKitList writes a table of the kits of a workspace.
+func writeKitList(outfile *os.File, w *project.Workspace) {
+	bw := bufio.NewWriter(outfile)
+	fmt.Fprintf(bw, "%-12s %-8s %-8s %-12s %-3s %-10s %s\r\n", "ID", "Vendor", "Build", "Test", "BED", "Added", "Source")
+	for _, k := range w.Kits {
+		bed := "no"
+		if k.BED {
+			bed = "yes"
+		}
+		fmt.Fprintf(bw, "%-12s %-8s %-8s %-12s %-3s %-10s %s\r\n",
+			k.ID, k.Vendor, k.Build, k.Test, bed, k.Added.Format("2006-01-02"), k.Source)
+	}
+	err := bw.Flush()
+	checkFatal(err, "Error writing kit list")
+}
+
+// workspaceDir returns the directory of the project workspace.
+func workspaceDir() string {
+	if dir := os.Getenv(ProjectEnv); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// workspaceKitFiles returns the SNP file of the kit with the given ID
+// in the project workspace. If id is empty, the files of all kits
+// are returned.
+func workspaceKitFiles(id string) ([]string, error) {
+	w, err := project.Open(workspaceDir())
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("opening workspace, %v", err))
+	}
+	var filenames []string
+	if id == "" {
+		for _, k := range w.Kits {
+			filenames = append(filenames, w.KitFile(k.ID))
+		}
+		return filenames, nil
+	}
+	if _, exists := w.Kit(id); !exists {
+		return nil, errors.New(fmt.Sprintf("unknown kit %s", id))
+	}
+	return append(filenames, w.KitFile(id)), nil
+}
+
+// workspaceBED returns the BED file of a kit file if the kit file
+// belongs to a project workspace and the kit has a BED file.
+// Otherwise it returns an empty string.
+func workspaceBED(kitFile string) string {
+	kitsDir := filepath.Dir(kitFile)
+	if filepath.Base(kitsDir) != project.KitsDir {
+		return ""
+	}
+	dir := filepath.Dir(kitsDir)
+	if !project.IsWorkspace(dir) {
+		return ""
+	}
+	id := filepath.Base(kitFile)
+	id = id[:len(id)-len(filepath.Ext(id))]
+	bedName := filepath.Join(dir, project.BedsDir, id+".bed")
+	if _, err := os.Stat(bedName); err != nil {
+		return ""
+	}
+	return bedName
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// queryResult is a data base record as it is written by Query.
+type queryResult struct {
+	Pos           int      `json:"pos"`
+	Ref           string   `json:"ref"`
+	Alt           string   `json:"alt"`
+	Name          string   `json:"name"`
+	Aliases       []string `json:"aliases,omitempty"`
+	Haplogroup    string   `json:"haplogroup,omitempty"`
+	YCCHaplogroup string   `json:"yccHaplogroup,omitempty"`
+	Mutation      string   `json:"mutation,omitempty"`
+	Reference     string   `json:"reference,omitempty"`
+	Comment       string   `json:"comment,omitempty"`
+	Source        string   `json:"source,omitempty"`
+}
+
+// Query searches the SNP data base by name, position, haplogroup
+// or comment. If several search criteria are given, only entries
+// that satisfy all criteria are reported.
+// cmdLine: command line parameters without the subcommand.
+func Query(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		name       = flags.String("name", "", "List of SNP names or aliases separated by commas.")
+		pos        = flags.String("pos", "", "Position or range of positions, for example 2887824 or 2800000-2900000.")
+		haplogroup = flags.String("haplogroup", "", "Haplogroup label. A trailing * includes all subclades, for example R1b1a1b*.")
+		comment    = flags.String("comment", "", "Text that must be contained in the comment.")
+		format     = flags.String("format", "csv", "Output format: csv or json.")
+		out        = flags.String("out", "", "Output file.")
+		isoggdb    = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db         = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache    = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+	)
+	flags.Parse(cmdLine)
+
+	if *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db not specified.\n")
+		os.Exit(1)
+	}
+	if *name == "" && *pos == "" && *haplogroup == "" && *comment == "" {
+		fmt.Printf("No search criteria specified. Use name, pos, haplogroup or comment.\n")
+		os.Exit(1)
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Printf("Parameter format must be csv or json.\n")
+		os.Exit(1)
+	}
+
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
+
+	// Search for each criterion and intersect the results.
+	var results [][]*snp.DBRecord
+	if *name != "" {
+		var recs []*snp.DBRecord
+		for _, n := range strings.Split(*name, ",") {
+			recs = append(recs, snpDB.EntriesByNameOrAlias(strings.TrimSpace(n))...)
+		}
+		results = append(results, recs)
+	}
+	if *pos != "" {
+		start, end, err := parsePositionRange(*pos)
+		checkFatal(err, "Error parsing parameter pos")
+		results = append(results, snpDB.EntriesInRange(start, end))
+	}
+	if *haplogroup != "" {
+		results = append(results, snpDB.EntriesByHaplogroup(*haplogroup))
+	}
+	if *comment != "" {
+		results = append(results, snpDB.EntriesByComment(*comment))
+	}
+	recs := intersectDBRecords(results)
+	snp.SortDBRecords(recs)
+
+	queryResults := make([]queryResult, len(recs))
+	for i, r := range recs {
+		queryResults[i] = queryResult{
+			Pos:           r.Key.Pos,
+			Ref:           r.Key.Ref,
+			Alt:           r.Key.Alt,
+			Name:          r.Name,
+			Aliases:       snpDB.Aliases(r),
+			Haplogroup:    r.Haplogroup,
+			YCCHaplogroup: r.YCCHaplogroup,
+			Mutation:      r.Mutation,
+			Reference:     r.Reference,
+			Comment:       r.Comment,
+			Source:        r.Source,
+		}
+	}
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	w := bufio.NewWriter(outfile)
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(queryResults)
+		checkFatal(err, "Error writing JSON output")
+	} else {
+		// Columns: Pos, Ref, Alt, Name, Comment, Haplogroup, Aliases,
+		// YCCHaplogroup, Mutation, Reference.
+		// The first five columns are compatible with the annotated CSV format.
+		csvWriter := csv.NewWriter(w)
+		csvWriter.UseCRLF = true
+		for _, r := range queryResults {
+			csvWriter.Write([]string{strconv.Itoa(r.Pos), r.Ref, r.Alt, r.Name, r.Comment, r.Haplogroup, strings.Join(r.Aliases, "/"),
+				r.YCCHaplogroup, r.Mutation, r.Reference})
+		}
+		csvWriter.Flush()
+		checkFatal(csvWriter.Error(), "Error writing CSV output")
+	}
+	err = w.Flush()
+	checkFatal(err, "Error writing output")
+}
+
+// parsePositionRange parses a single position or a range of
+// positions in the form start-end.
+func parsePositionRange(param string) (start, end int, err error) {
+	parts := strings.SplitN(param, "-", 2)
+	start, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return start, end, err
+	}
+	end = start
+	if len(parts) == 2 {
+		end, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	}
+	return start, end, err
+}
+
+// intersectDBRecords returns the records that are contained in
+// all lists. Duplicates are removed.
+func intersectDBRecords(lists [][]*snp.DBRecord) []*snp.DBRecord {
+	var result []*snp.DBRecord
+	if len(lists) == 0 {
+		return result
+	}
+	counts := make(map[*snp.DBRecord]int)
+	for _, list := range lists {
+		seen := make(map[*snp.DBRecord]bool)
+		for _, r := range list {
+			if !seen[r] {
+				seen[r] = true
+				counts[r]++
+			}
+		}
+	}
+	for _, r := range lists[0] {
+		if counts[r] == len(lists) {
+			result = append(result, r)
+			counts[r] = 0
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+func TestParsePositionRange(t *testing.T) {
+	tests := []struct {
+		param      string
+		start, end int
+		err        bool
+	}{
+		{param: "2887824", start: 2887824, end: 2887824},
+		{param: "2800000-2900000", start: 2800000, end: 2900000},
+		{param: " 100 - 200 ", start: 100, end: 200},
+		{param: "abc", err: true},
+		{param: "100-", err: true},
+	}
+	for _, test := range tests {
+		start, end, err := parsePositionRange(test.param)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v", test.param, err)
+			continue
+		}
+		if !test.err && (start != test.start || end != test.end) {
+			t.Errorf("%s: got %d-%d, want %d-%d", test.param, start, end, test.start, test.end)
+		}
+	}
+}
+
+func TestIntersectDBRecords(t *testing.T) {
+	a, b, c := &snp.DBRecord{Name: "a"}, &snp.DBRecord{Name: "b"}, &snp.DBRecord{Name: "c"}
+	tests := []struct {
+		name  string
+		lists [][]*snp.DBRecord
+		want  []*snp.DBRecord
+	}{
+		{"no lists", nil, nil},
+		{"single list with duplicates", [][]*snp.DBRecord{{a, b, a}}, []*snp.DBRecord{a, b}},
+		{"two lists", [][]*snp.DBRecord{{a, b, c}, {c, a}}, []*snp.DBRecord{a, c}},
+		{"duplicates in one list only", [][]*snp.DBRecord{{a, a}, {b}}, nil},
+	}
+	for _, test := range tests {
+		got := intersectDBRecords(test.lists)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %d records, want %d", test.name, len(got), len(test.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s: got %s at %d, want %s", test.name, got[i].Name, i, test.want[i].Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Resolve converts lists of SNP names into SNPs by looking them
+// up in the SNP data base. Names that could not be resolved or
+// that are ambiguous are reported.
+// cmdLine: command line parameters without the subcommand.
+func Resolve(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in      = flags.String("in", "", "List of text files with SNP names separated by commas.")
+		names   = flags.String("names", "", "List of SNP names separated by commas.")
+		out     = flags.String("out", "", "Output file for list of SNPs in CSV format.")
+		report  = flags.String("report", "", "Output file for names that could not be resolved or are ambiguous.")
+		isoggdb = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format.")
+		db      = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" && *names == "" {
+		fmt.Printf("Parameter in or names not specified.\n")
+		os.Exit(1)
+	}
+	if *isoggdb == "" && *db == "" {
+		fmt.Printf("Parameter isoggdb or db not specified.\n")
+		os.Exit(1)
+	}
+
+	snpDB, _, err := dbFromParameters(*isoggdb, *db, *nocache)
+	checkFatal(err, "Error reading SNP data base")
+
+	// Collect names.
+	snpNames := snp.SplitNames(*names)
+	if *in != "" {
+		for _, filename := range strings.Split(*in, ",") {
+			n, err := snp.ReadNames(filename)
+			checkFatal(err, "Error reading names file")
+			snpNames = append(snpNames, n...)
+		}
+	}
+
+	// Resolve names.
+	var records snp.CSVRecords
+	var problems []string
+	seen := make(map[snp.SNP]bool)
+	for _, name := range snpNames {
+		entries := snpDB.Resolve(name)
+		switch {
+		case len(entries) == 0:
+			problems = append(problems, fmt.Sprintf("unresolved: %s", name))
+		case len(entries) > 1:
+			var positions []string
+			for _, e := range entries {
+				positions = append(positions, fmt.Sprintf("%s %d", e.Name, e.Key.Pos))
+			}
+			problems = append(problems, fmt.Sprintf("ambiguous: %s (%s)", name, strings.Join(positions, ", ")))
+		case !seen[entries[0].Key]:
+			e := entries[0]
+			seen[e.Key] = true
+			records = append(records, snp.CSVRecord{
+				Pos:     strconv.Itoa(e.Key.Pos),
+				Ref:     e.Key.Ref,
+				Alt:     e.Key.Alt,
+				Name:    name,
+				Comment: e.Description()})
+		}
+	}
+
+	// Write SNPs.
+	records.Sort()
+	if *out != "" {
+		err := records.WriteCSV(*out)
+		checkFatal(err, "Error writing to CSV file")
+	} else {
+		for _, r := range records {
+			os.Stdout.WriteString(r.String())
+		}
+	}
+
+	// Write problems to the report file or as comments to stdout.
+	if *report != "" {
+		outfile, err := os.Create(*report)
+		checkFatal(err, "Error creating report file")
+		defer outfile.Close()
+		w := bufio.NewWriter(outfile)
+		for _, p := range problems {
+			w.WriteString(p + "\r\n")
+		}
+		err = w.Flush()
+		checkFatal(err, "Error writing report file")
+	} else {
+		for _, p := range problems {
+			fmt.Printf("# %s\n", p)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+	"github.com/yogischogi/phylosnip/tree"
+)
+
+// Tree builds a phylogenetic tree from the SNPs of several kits.
+// Each SNP is assigned to a branch. SNPs found in only one kit are
+// listed as private SNPs of the kit. SNPs that do not fit into the
+// tree are reported as conflicts. Alternatively an existing tree in
+// Newick, NEXUS, YFull or FTDNA format can be read and converted.
+// cmdLine: command line parameters without the subcommand.
+func Tree(cmdLine []string) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	var (
+		in        = flags.String("in", "", "Input list of kit CSV files or directories separated by commas.")
+		beds      = flags.String("beds", "", "Directory with BED files of callable regions, named like the kit files.")
+		treeFile  = flags.String("tree", "", "Input tree file, used instead of parameter in.")
+		treeFmt   = flags.String("treeformat", "auto", "Format of the tree file: newick (also NEXUS), yfull (JSON), yfullcsv, ftdna (JSON) or auto.")
+		isoggdb   = flags.String("isoggdb", "", "Input file for ISOGG SNP data base in CSV format, used to find the positions of SNP names in the tree file.")
+		db        = flags.String("db", "", "List of SNP data base sources separated by commas, for example isogg:snps_hg38.csv,csv:private.csv.")
+		nocache   = flags.Bool("nocache", false, "If nocache=true the binary cache for the ISOGG data base is not used.")
+		report    = flags.String("report", "", "Output file for SNP names of the tree file that could not be resolved or are ambiguous.")
+		out       = flags.String("out", "", "Output file.")
+		format    = flags.String("format", "text", "Output format: text, newick, nexus, svg or dot.")
+		maxSNPs   = flags.Int("maxsnps", 5, "Maximum number of SNPs shown for a branch in svg and dot format. 0 shows all SNPs.")
+		normalize = flags.Bool("normalize", false, "If normalize=true branch lengths are divided by the callable length of the branch. Requires BED files for all kits.")
+	)
+	flags.Parse(cmdLine)
+
+	if *in == "" && *treeFile == "" {
+		fmt.Printf("Parameter in or tree not specified.\n")
+		os.Exit(1)
+	}
+	switch *format {
+	case "text", "newick", "nexus", "svg", "dot":
+	default:
+		fmt.Printf("Parameter format must be text, newick, nexus, svg or dot.\n")
+		os.Exit(1)
+	}
+
+	var t *tree.Tree
+	var err error
+	if *treeFile != "" {
+		t, err = readTree(*treeFile, *treeFmt)
+		checkFatal(err, "Error reading tree file")
+		_, err = linkTree(t, *isoggdb, *db, *nocache, *report)
+		checkFatal(err, "Error linking tree to SNP data base")
+	} else {
+		t, err = buildTree(*in, *beds)
+		checkFatal(err, "Error reading kits")
+	}
+
+	outfile := os.Stdout
+	if *out != "" {
+		outfile, err = os.Create(*out)
+		checkFatal(err, "Error creating output file")
+		defer outfile.Close()
+	}
+	switch *format {
+	case "text":
+		err = t.Write(outfile)
+	case "newick":
+		err = t.WriteNewick(outfile, *normalize)
+	case "nexus":
+		err = t.WriteNEXUS(outfile, *normalize)
+	case "svg":
+		err = t.WriteSVG(outfile, tree.Style{MaxSNPs: *maxSNPs})
+	case "dot":
+		err = t.WriteDOT(outfile, tree.Style{MaxSNPs: *maxSNPs})
+	}
+	checkFatal(err, "Error writing tree")
+}
+
+// readTree reads a tree from a file. format is newick (also for
+// NEXUS files), yfull (YFull JSON), yfullcsv (YFull CSV), ftdna
+// (FTDNA haplotree JSON) or auto. For auto, the format is derived
+// from the file extension. JSON files are FTDNA haplotrees if they
+// contain the key allNodes.
+func readTree(filename, format string) (*tree.Tree, error) {
+	if format == "auto" || format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			format = "yfullcsv"
+		case ".json":
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			format = "yfull"
+			if strings.Contains(string(data), `"allNodes"`) {
+				format = "ftdna"
+			}
+		default:
+			format = "newick"
+		}
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	switch format {
+	case "newick":
+		return tree.ReadNewick(file)
+	case "yfull":
+		return tree.ReadYFullJSON(file)
+	case "yfullcsv":
+		return tree.ReadYFullCSV(file)
+	case "ftdna":
+		return tree.ReadFTDNA(file)
+	}
+	return nil, errors.New(fmt.Sprintf("unknown tree format %s", format))
+}
+
+// linkTree links the SNP names of a tree to the SNP data base specified
+// by isoggdb and sources like dbFromParameters does. Names that could
+// not be resolved are written to the file report if it is not empty.
+// If no data base is specified, the tree is not changed and nil is returned.
+func linkTree(t *tree.Tree, isoggdb, sources string, nocache bool, report string) (*snp.DB, error) {
+	snpDB, _, err := dbFromParameters(isoggdb, sources, nocache)
+	if err != nil || snpDB == nil {
+		return snpDB, err
+	}
+	unresolved := t.Link(snpDB)
+	if report == "" {
+		return snpDB, nil
+	}
+	outfile, err := os.Create(report)
+	if err != nil {
+		return snpDB, err
+	}
+	defer outfile.Close()
+	w := bufio.NewWriter(outfile)
+	for _, name := range unresolved {
+		w.WriteString("unresolved: " + name + "\r\n")
+	}
+	return snpDB, w.Flush()
+}
+
+// buildTree builds a tree from the kits specified by in and beds
+// like readKits does.
+func buildTree(in, beds string) (*tree.Tree, error) {
+	kits, err := readKits(in, beds)
+	if err != nil {
+		return nil, err
+	}
+	if len(kits) == 0 {
+		return nil, errors.New("no kit files found")
+	}
+	names := make([]string, len(kits))
+	sets := make([]snp.SNPs, len(kits))
+	callable := make([]snp.BEDRegions, len(kits))
+	annotations := make(snp.Annotations)
+	callableByName := make(map[string]snp.BEDRegions)
+	for i, k := range kits {
+		names[i], sets[i], callable[i] = k.name, k.snps, k.callable
+		annotations.Merge(k.annotations)
+		if k.callable != nil {
+			callableByName[k.name] = k.callable
+		}
+	}
+	t := tree.Build(snp.NewMatrix(names, sets, callable), annotations)
+	t.SetCallable(callableByName)
+	return t, nil
+}
@@ -30,30 +30,27 @@ func Union(cmdLine []string) {
 	checkFatal(err, "Error parsing parameter in")
 
 	// Calculate union.
-	var snps snp.SNPs = make(map[snp.SNP]bool)
-	err = unionWithFiles(snps, filenames)
+	var snps snp.SNPs
+	annotations := make(snp.Annotations)
+	err = unionWithFiles(&snps, annotations, filenames)
 	checkFatal(err, "Error calculating the union of files")
 
 	// Output SNPs.
-	if *out != "" {
-		err := snps.WriteCSV(*out)
-		checkFatal(err, "Error writing SNPs to CSV output file")
-	} else {
-		for snp, _ := range snps {
-			fmt.Print(snp.String())
-		}
-	}
+	err = writeSNPs(snps, annotations, *out)
+	checkFatal(err, "Error writing SNPs to CSV output file")
 }
 
 // unionWithFiles calculates the set union of snps with the SNPs
-// contained in the files specified by filenames.
-func unionWithFiles(snps snp.SNPs, filenames []string) error {
+// contained in the files specified by filenames. The names and comments
+// of the SNPs are merged into annotations.
+func unionWithFiles(snps *snp.SNPs, annotations snp.Annotations, filenames []string) error {
 	for _, filename := range filenames {
-		s, err := snp.ReadCSV(filename)
+		s, a, err := snp.ReadAnnotatedCSV(filename)
 		if err != nil {
 			return errors.New(fmt.Sprintf("reading CSV file, %v", err))
 		}
 		snps.Union(s)
+		annotations.Merge(a)
 	}
 	return nil
 }
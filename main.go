@@ -26,8 +26,40 @@ func main() {
 			"        calculates the intersection of SNPs from CSV files.\n" +
 			"    difference\n" +
 			"        calculates the difference of SNPs from CSV files.\n" +
+			"    atleast\n" +
+			"        finds SNPs that are contained in at least k CSV files.\n" +
+			"    eval\n" +
+			"        evaluates set expressions over CSV files.\n" +
+			"    matrix\n" +
+			"        writes a presence/absence matrix of SNPs across kits.\n" +
+			"    distance\n" +
+			"        calculates pairwise distances between kits.\n" +
+			"    tree\n" +
+			"        builds a phylogenetic tree from the SNPs of several kits.\n" +
+			"    place\n" +
+			"        finds the position of kits in a haplogroup tree.\n" +
+			"    check\n" +
+			"        checks whether a new kit fits into the project tree.\n" +
+			"    novels\n" +
+			"        finds novel SNPs shared by several kits.\n" +
+			"    age\n" +
+			"        estimates the ages of branches.\n" +
+			"    homoplasy\n" +
+			"        finds parallel mutations and reversions.\n" +
+			"    project\n" +
+			"        manages the kits of a project workspace.\n" +
 			"    lookup\n" +
-			"        adds ISOGG data base information to SNP CSV files.\n")
+			"        adds ISOGG data base information to SNP CSV files.\n" +
+			"    dbcheck\n" +
+			"        reports conflicts between SNP data base sources.\n" +
+			"    liftover\n" +
+			"        converts SNP and BED files between genome builds.\n" +
+			"    query\n" +
+			"        searches the SNP data base.\n" +
+			"    dbdiff\n" +
+			"        compares two versions of the SNP data base.\n" +
+			"    resolve\n" +
+			"        converts lists of SNP names into SNP CSV files.\n")
 		os.Exit(1)
 	}
 
@@ -47,8 +79,40 @@ func main() {
 		cmd.Intersection(os.Args[2:])
 	case "difference":
 		cmd.Difference(os.Args[2:])
+	case "atleast":
+		cmd.AtLeast(os.Args[2:])
+	case "eval":
+		cmd.Eval(os.Args[2:])
+	case "matrix":
+		cmd.Matrix(os.Args[2:])
+	case "distance":
+		cmd.Distance(os.Args[2:])
+	case "tree":
+		cmd.Tree(os.Args[2:])
+	case "place":
+		cmd.Place(os.Args[2:])
+	case "check":
+		cmd.Check(os.Args[2:])
+	case "novels":
+		cmd.Novels(os.Args[2:])
+	case "age":
+		cmd.Age(os.Args[2:])
+	case "homoplasy":
+		cmd.Homoplasy(os.Args[2:])
+	case "project":
+		cmd.Project(os.Args[2:])
 	case "lookup":
 		cmd.Lookup(os.Args[2:])
+	case "dbcheck":
+		cmd.DBCheck(os.Args[2:])
+	case "liftover":
+		cmd.Liftover(os.Args[2:])
+	case "query":
+		cmd.Query(os.Args[2:])
+	case "dbdiff":
+		cmd.DBDiff(os.Args[2:])
+	case "resolve":
+		cmd.Resolve(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", os.Args[1])
 	}
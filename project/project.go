@@ -0,0 +1,241 @@
+// Package project provides workspaces that manage the kits of a
+// project together with their metadata.
+//
+// A workspace is a directory with a manifest file, a directory kits
+// with one SNP CSV file per kit and a directory beds with the BED
+// files of the callable regions. Kit files are named after the kit IDs.
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestName is the filename of the manifest in a workspace.
+const ManifestName = "phylosnip.json"
+
+// Names of the subdirectories of a workspace.
+const (
+	KitsDir = "kits"
+	BedsDir = "beds"
+)
+
+// Kit contains the metadata of a kit.
+type Kit struct {
+	ID string `json:"id"`
+	// Vendor is the testing company, for example FTDNA or YFull.
+	Vendor string `json:"vendor,omitempty"`
+	// Build is the reference genome build, for example hg38.
+	Build string `json:"build,omitempty"`
+	// Test is the type of the test, for example Big Y-700.
+	Test string `json:"test,omitempty"`
+	// Source is the absolute name of the file the kit was added from.
+	Source string `json:"source"`
+	// SHA256 is the checksum of the source file.
+	SHA256 string `json:"sha256"`
+	// BED is true if the kit has a BED file.
+	BED   bool      `json:"bed"`
+	Added time.Time `json:"added"`
+}
+
+// Workspace is a project directory with a kit registry.
+type Workspace struct {
+	Dir  string `json:"-"`
+	Kits []Kit  `json:"kits"`
+}
+
+// Init creates a new workspace in the directory dir.
+func Init(dir string) (*Workspace, error) {
+	if _, err := os.Stat(filepath.Join(dir, ManifestName)); err == nil {
+		return nil, errors.New(fmt.Sprintf("%s already contains a workspace", dir))
+	}
+	for _, sub := range []string{KitsDir, BedsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, err
+		}
+	}
+	w := &Workspace{Dir: dir}
+	return w, w.Save()
+}
+
+// Open opens the workspace in the directory dir.
+func Open(dir string) (*Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	w := &Workspace{Dir: dir}
+	if err := json.Unmarshal(data, w); err != nil {
+		return nil, errors.New(fmt.Sprintf("reading manifest, %v", err))
+	}
+	return w, nil
+}
+
+// IsWorkspace returns true if the directory dir contains a workspace.
+func IsWorkspace(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ManifestName))
+	return err == nil
+}
+
+// Save writes the manifest. The kits are sorted by ID.
+// The manifest is written to a temporary file that replaces the
+// old manifest, so that an interrupted run does not corrupt it.
+func (w *Workspace) Save() error {
+	sort.Slice(w.Kits, func(i, j int) bool { return w.Kits[i].ID < w.Kits[j].ID })
+	data, err := json.MarshalIndent(w, "", "\t")
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(w.Dir, ManifestName+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filepath.Join(w.Dir, ManifestName))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// Kit returns the kit with the given ID.
+func (w *Workspace) Kit(id string) (kit Kit, exists bool) {
+	for _, k := range w.Kits {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return kit, false
+}
+
+// KitFile returns the name of the SNP CSV file of a kit.
+func (w *Workspace) KitFile(id string) string {
+	return filepath.Join(w.Dir, KitsDir, id+".csv")
+}
+
+// BEDFile returns the name of the BED file of a kit.
+func (w *Workspace) BEDFile(id string) string {
+	return filepath.Join(w.Dir, BedsDir, id+".bed")
+}
+
+// Add adds a kit to the workspace. The SNP CSV file snpFile and
+// the optional BED file bedFile are copied into the workspace.
+// The source and checksum of the kit are set from snpFile.
+// Existing files that are not registered are not overwritten.
+// The manifest is not saved.
+func (w *Workspace) Add(kit Kit, snpFile, bedFile string) error {
+	if kit.ID == "" || kit.ID != filepath.Base(kit.ID) || kit.ID[0] == '.' {
+		return errors.New(fmt.Sprintf("invalid kit ID %q", kit.ID))
+	}
+	if _, exists := w.Kit(kit.ID); exists {
+		return errors.New(fmt.Sprintf("kit %s already exists", kit.ID))
+	}
+	checksum, err := fileChecksum(snpFile)
+	if err != nil {
+		return err
+	}
+	for _, k := range w.Kits {
+		if k.SHA256 == checksum {
+			return errors.New(fmt.Sprintf("%s is already registered as kit %s", snpFile, k.ID))
+		}
+	}
+	source, err := filepath.Abs(snpFile)
+	if err != nil {
+		return err
+	}
+	files := []string{w.KitFile(kit.ID)}
+	if bedFile != "" {
+		files = append(files, w.BEDFile(kit.ID))
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			return errors.New(fmt.Sprintf("%s exists but is not registered", f))
+		}
+	}
+	if err := copyFile(snpFile, w.KitFile(kit.ID)); err != nil {
+		os.Remove(w.KitFile(kit.ID))
+		return err
+	}
+	if bedFile != "" {
+		if err := copyFile(bedFile, w.BEDFile(kit.ID)); err != nil {
+			os.Remove(w.KitFile(kit.ID))
+			os.Remove(w.BEDFile(kit.ID))
+			return err
+		}
+	}
+	kit.Source = source
+	kit.SHA256 = checksum
+	kit.BED = bedFile != ""
+	if kit.Added.IsZero() {
+		kit.Added = time.Now().UTC().Truncate(time.Second)
+	}
+	w.Kits = append(w.Kits, kit)
+	return nil
+}
+
+// Remove removes a kit and its files from the workspace.
+// The manifest is not saved.
+func (w *Workspace) Remove(id string) error {
+	for i, k := range w.Kits {
+		if k.ID != id {
+			continue
+		}
+		if err := os.Remove(w.KitFile(id)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Remove(w.BEDFile(id)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		w.Kits = append(w.Kits[:i], w.Kits[i+1:]...)
+		return nil
+	}
+	return errors.New(fmt.Sprintf("unknown kit %s", id))
+}
+
+// fileChecksum returns the hexadecimal SHA-256 checksum of a file.
+func fileChecksum(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyFile copies the file src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
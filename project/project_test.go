@@ -0,0 +1,226 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes a test file into dir and returns its name.
+func writeFile(t *testing.T, dir, name, content string) string {
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// exists tests if a file exists.
+func exists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+func TestInitOpen(t *testing.T) {
+	dir := t.TempDir()
+	if IsWorkspace(dir) {
+		t.Errorf("empty directory is a workspace")
+	}
+	if _, err := Init(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !IsWorkspace(dir) {
+		t.Errorf("initialized directory is not a workspace")
+	}
+	for _, sub := range []string{KitsDir, BedsDir} {
+		if !exists(filepath.Join(dir, sub)) {
+			t.Errorf("directory %s not created", sub)
+		}
+	}
+	if _, err := Init(dir); err == nil {
+		t.Errorf("second Init succeeded")
+	}
+	w, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Kits) != 0 {
+		t.Errorf("new workspace contains kits %v", w.Kits)
+	}
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Errorf("opening a directory without workspace succeeded")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	src := t.TempDir()
+	snpA := writeFile(t, src, "a.csv", "100,A,G\r\n")
+	snpB := writeFile(t, src, "b.csv", "200,C,T\r\n")
+	bed := writeFile(t, src, "a.bed", "chrY\t0\t1000\n")
+
+	tests := []struct {
+		name    string
+		kit     Kit
+		snpFile string
+		bedFile string
+		// existing is a file name in the kits directory that exists
+		// before the kit is added.
+		existing string
+		ok       bool
+	}{
+		{"with BED", Kit{ID: "A1"}, snpA, bed, "", true},
+		{"without BED", Kit{ID: "B1"}, snpB, "", "", true},
+		{"empty ID", Kit{ID: ""}, snpA, "", "", false},
+		{"ID with path", Kit{ID: "x/A1"}, snpA, "", "", false},
+		{"hidden ID", Kit{ID: ".A1"}, snpA, "", "", false},
+		{"missing SNP file", Kit{ID: "A1"}, filepath.Join(src, "missing.csv"), "", "", false},
+		{"unregistered file", Kit{ID: "A1"}, snpA, "", "A1.csv", false},
+		{"missing BED file", Kit{ID: "A1"}, snpA, filepath.Join(src, "missing.bed"), "", false},
+	}
+	for _, test := range tests {
+		w, err := Init(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.existing != "" {
+			writeFile(t, filepath.Join(w.Dir, KitsDir), test.existing, "unregistered")
+		}
+		err = w.Add(test.kit, test.snpFile, test.bedFile)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: got error %v", test.name, err)
+			continue
+		}
+		if !test.ok {
+			if len(w.Kits) != 0 {
+				t.Errorf("%s: kit registered", test.name)
+			}
+			if test.existing == "" && (exists(w.KitFile(test.kit.ID)) || exists(w.BEDFile(test.kit.ID))) {
+				t.Errorf("%s: copied files not removed", test.name)
+			}
+			if test.existing != "" {
+				data, _ := os.ReadFile(filepath.Join(w.Dir, KitsDir, test.existing))
+				if string(data) != "unregistered" {
+					t.Errorf("%s: unregistered file overwritten", test.name)
+				}
+			}
+			continue
+		}
+		kit, found := w.Kit(test.kit.ID)
+		if !found {
+			t.Errorf("%s: kit not registered", test.name)
+			continue
+		}
+		if !filepath.IsAbs(kit.Source) || kit.Source != test.snpFile {
+			t.Errorf("%s: got source %s, want %s", test.name, kit.Source, test.snpFile)
+		}
+		if kit.SHA256 == "" || kit.Added.IsZero() {
+			t.Errorf("%s: checksum or date not set", test.name)
+		}
+		if kit.BED != (test.bedFile != "") || exists(w.BEDFile(kit.ID)) != kit.BED {
+			t.Errorf("%s: got BED %v", test.name, kit.BED)
+		}
+		if !exists(w.KitFile(kit.ID)) {
+			t.Errorf("%s: SNP file not copied", test.name)
+		}
+	}
+}
+
+func TestAddRelativeSource(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, src, "a.csv", "100,A,G\r\n")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(src); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	w, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(Kit{ID: "A1"}, "a.csv", ""); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(src, "a.csv"); w.Kits[0].Source != want {
+		t.Errorf("got source %s, want %s", w.Kits[0].Source, want)
+	}
+}
+
+func TestAddDuplicate(t *testing.T) {
+	src := t.TempDir()
+	snpA := writeFile(t, src, "a.csv", "100,A,G\r\n")
+	copyA := writeFile(t, src, "copy.csv", "100,A,G\r\n")
+	w, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(Kit{ID: "A1"}, snpA, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(Kit{ID: "A1"}, writeFile(t, src, "b.csv", "200,C,T\r\n"), ""); err == nil {
+		t.Errorf("duplicate ID accepted")
+	}
+	if err := w.Add(Kit{ID: "A2"}, copyA, ""); err == nil {
+		t.Errorf("duplicate file accepted")
+	}
+	if exists(w.KitFile("A2")) {
+		t.Errorf("file of rejected kit copied")
+	}
+	if len(w.Kits) != 1 {
+		t.Errorf("got %d kits, want 1", len(w.Kits))
+	}
+}
+
+func TestSaveRemove(t *testing.T) {
+	src := t.TempDir()
+	w, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"C1", "A1", "B1"} {
+		snpFile := writeFile(t, src, id+".csv", id+"\r\n")
+		bedFile := writeFile(t, src, id+".bed", id+"\n")
+		if err := w.Add(Kit{ID: id, Vendor: "FTDNA"}, snpFile, bedFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Remove("B1"); err != nil {
+		t.Fatal(err)
+	}
+	if exists(w.KitFile("B1")) || exists(w.BEDFile("B1")) {
+		t.Errorf("files of removed kit not deleted")
+	}
+	if err := w.Remove("B1"); err == nil {
+		t.Errorf("removing an unknown kit succeeded")
+	}
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The manifest is replaced without leaving temporary files.
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() && e.Name() != ManifestName {
+			t.Errorf("unexpected file %s", e.Name())
+		}
+	}
+
+	reopened, err := Open(w.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reopened.Kits) != 2 || reopened.Kits[0].ID != "A1" || reopened.Kits[1].ID != "C1" {
+		t.Fatalf("got kits %v, want A1, C1", reopened.Kits)
+	}
+	for i, kit := range reopened.Kits {
+		if kit != w.Kits[i] {
+			t.Errorf("got kit %v, want %v", kit, w.Kits[i])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package snp
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Annotation contains the name and comment of an SNP.
+type Annotation struct {
+	Name    string
+	Comment string
+}
+
+// Annotations maps SNPs to their annotations.
+// SNPs without name and comment are not included.
+type Annotations map[SNP]Annotation
+
+// ReadAnnotatedCSV reads SNPs and their annotations from a CSV file
+// with the columns Pos, Ref, Alt and the optional columns Name and
+// Comment. Lines with the position NoPosition, as they are written
+// by filterftdna, are skipped, because set operations need positions.
+// Like ReadCSV it returns an error for lines with other non-numeric
+// positions or with less than three columns.
+func ReadAnnotatedCSV(filename string) (SNPs, Annotations, error) {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer infile.Close()
+
+	csvReader := csv.NewReader(infile)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	var snps []SNP
+	annotations := make(Annotations)
+	for {
+		fields, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(fields) < 3 {
+			return nil, nil, errors.New(fmt.Sprintf("line %d: missing SNP columns", line))
+		}
+		if fields[0] == NoPosition {
+			continue
+		}
+		pos, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("line %d: parsing SNP position %v", line, err))
+		}
+		s := SNP{Pos: pos, Ref: fields[1], Alt: fields[2]}
+		snps = append(snps, s)
+		var annotation Annotation
+		if len(fields) > 3 {
+			annotation.Name = fields[3]
+		}
+		if len(fields) > 4 {
+			annotation.Comment = fields[4]
+		}
+		if annotation.Name != "" || annotation.Comment != "" {
+			annotations.Add(s, annotation)
+		}
+	}
+	return NewSNPs(snps), annotations, nil
+}
+
+// Add adds an annotation for an SNP. If the SNP is already annotated,
+// the annotations are merged. Different names are joined by slashes
+// and different comments by semicolons.
+func (a Annotations) Add(s SNP, annotation Annotation) {
+	existing := a[s]
+	existing.Name = joinDistinct(existing.Name, annotation.Name, "/")
+	existing.Comment = joinDistinct(existing.Comment, annotation.Comment, "; ")
+	a[s] = existing
+}
+
+// Merge adds all annotations of other.
+func (a Annotations) Merge(other Annotations) {
+	for s, annotation := range other {
+		a.Add(s, annotation)
+	}
+}
+
+// Records converts SNPs into CSV records with their annotations.
+func (a Annotations) Records(snps SNPs) CSVRecords {
+	result := make(CSVRecords, len(snps))
+	for i, s := range snps {
+		annotation := a[s]
+		result[i] = CSVRecord{
+			Pos:     strconv.Itoa(s.Pos),
+			Ref:     s.Ref,
+			Alt:     s.Alt,
+			Name:    annotation.Name,
+			Comment: annotation.Comment}
+	}
+	return result
+}
+
+// joinDistinct appends the parts of b to a unless they are already
+// contained in a. Parts are separated by sep.
+func joinDistinct(a, b, sep string) string {
+	if a == "" {
+		return b
+	}
+	parts := strings.Split(a, sep)
+	for _, p := range strings.Split(b, sep) {
+		found := p == ""
+		for _, existing := range parts {
+			if p == existing {
+				found = true
+				break
+			}
+		}
+		if !found {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, sep)
+}
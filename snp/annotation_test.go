@@ -0,0 +1,143 @@
+package snp
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to a file in a temporary directory.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestReadAnnotatedCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		snps        string
+		annotations map[int]Annotation
+		err         string
+	}{
+		{
+			name:    "plain SNPs",
+			content: "300,C,T\r\n100,A,C\r\n",
+			snps:    "100 300",
+		},
+		{
+			name:    "annotations",
+			content: "# comment\r\n100,A,C,Z1,\"first, with comma\"\r\n200,G,T,,\r\n300,C,T,L21\r\n",
+			snps:    "100 200 300",
+			annotations: map[int]Annotation{
+				100: {Name: "Z1", Comment: "first, with comma"},
+				300: {Name: "L21"},
+			},
+		},
+		{
+			name:    "duplicate SNP with different names",
+			content: "300,C,T,L21\r\n300,C,T,S145,x\r\n",
+			snps:    "300",
+			annotations: map[int]Annotation{
+				300: {Name: "L21/S145", Comment: "x"},
+			},
+		},
+		{
+			name:    "position unknown",
+			content: "n/a,C,T,BY123,\r\n100,A,C,Z1,\r\n",
+			snps:    "100",
+			annotations: map[int]Annotation{
+				100: {Name: "Z1"},
+			},
+		},
+		{
+			name:    "invalid position",
+			content: "100,A,C\r\nabc,C,T\r\n",
+			err:     "line 2",
+		},
+		{
+			name:    "missing columns",
+			content: "100,A,C\r\n200,G\r\n",
+			err:     "line 2",
+		},
+	}
+	for _, test := range tests {
+		snps, annotations, err := ReadAnnotatedCSV(writeFile(t, "kit.csv", test.content))
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		var got []string
+		for _, s := range snps {
+			got = append(got, strconv.Itoa(s.Pos))
+		}
+		if strings.Join(got, " ") != test.snps {
+			t.Errorf("%s: got SNPs %v, want %s", test.name, got, test.snps)
+		}
+		if len(annotations) != len(test.annotations) {
+			t.Errorf("%s: got annotations %v, want %v", test.name, annotations, test.annotations)
+			continue
+		}
+		for s, a := range annotations {
+			if a != test.annotations[s.Pos] {
+				t.Errorf("%s: got annotation %v for %d, want %v", test.name, a, s.Pos, test.annotations[s.Pos])
+			}
+		}
+	}
+}
+
+// TestFTDNAAnnotations reads the output of filterftdna, which
+// contains SNPs without position, and combines it with another
+// kit like union does.
+func TestFTDNAAnnotations(t *testing.T) {
+	db := NewDB()
+	db.Add(DBRecord{Key: SNP{Pos: 20577481, Ref: "C", Alt: "G"}, Name: "L21", Haplogroup: "R1b1a1b1a1a2c"})
+	ftdna := "Type,Position,SNPName,Derived,OnTree,Reference,Genotype,Confidence\r\n" +
+		"Known SNP,n/a,L21,Yes(+),Yes,C,G,PASS\r\n" +
+		"Known SNP,n/a,BY123,Yes(+),Yes,A,G,PASS\r\n" +
+		"Novel Variant,7000000,,,,A,T,PASS\r\n"
+	recs, err := ReadFTDNAcsv(writeFile(t, "ftdna.csv", ftdna), true, false, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := filepath.Join(t.TempDir(), "ftdna-filtered.csv")
+	recs.Sort()
+	if err := recs.WriteCSV(filtered); err != nil {
+		t.Fatal(err)
+	}
+
+	snps, annotations, err := ReadAnnotatedCSV(filtered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, otherAnnotations, err := ReadAnnotatedCSV(writeFile(t, "other.csv", "20577481,C,G,S145,\r\n9000000,G,C\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snps.Union(other)
+	annotations.Merge(otherAnnotations)
+	var got []string
+	for _, r := range annotations.Records(snps) {
+		got = append(got, strings.Join([]string{r.Pos, r.Ref, r.Alt, r.Name, r.Comment}, ","))
+	}
+	want := []string{
+		"7000000,A,T,,",
+		"9000000,G,C,,",
+		"20577481,C,G,L21/S145,R1b1a1b1a1a2c",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -1,8 +1,11 @@
 package snp
 
 import (
+	"bufio"
 	"encoding/csv"
+	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -30,6 +33,84 @@ func (b *BEDRegions) Includes(pos int) bool {
 	return false
 }
 
+// Normalize sorts the regions by their start positions
+// and merges overlapping or adjacent regions.
+func (b *BEDRegions) Normalize() {
+	regions := *b
+	if len(regions) == 0 {
+		return
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+	merged := regions[:1]
+	for _, r := range regions[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			if r.End > last.End {
+				last.End = r.End
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	*b = merged
+}
+
+// Length returns the number of base pairs in the regions.
+// Overlapping regions are counted only once.
+func (b BEDRegions) Length() int {
+	regions := append(BEDRegions(nil), b...)
+	regions.Normalize()
+	length := 0
+	for _, r := range regions {
+		length += r.End - r.Start
+	}
+	return length
+}
+
+// Intersection returns the regions that are contained in b and in other.
+// The result is sorted and does not contain overlapping regions.
+func (b BEDRegions) Intersection(other BEDRegions) BEDRegions {
+	x := append(BEDRegions(nil), b...)
+	y := append(BEDRegions(nil), other...)
+	x.Normalize()
+	y.Normalize()
+	var result BEDRegions
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		start, end := x[i].Start, x[i].End
+		if y[j].Start > start {
+			start = y[j].Start
+		}
+		if y[j].End < end {
+			end = y[j].End
+		}
+		if start < end {
+			result = append(result, BEDRegion{Start: start, End: end})
+		}
+		if x[i].End < y[j].End {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// WriteBED writes the regions to a BED file.
+func (b BEDRegions) WriteBED(filename string) error {
+	outfile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer outfile.Close()
+
+	w := bufio.NewWriter(outfile)
+	for _, r := range b {
+		fmt.Fprintf(w, "chrY\t%d\t%d\n", r.Start, r.End)
+	}
+	return w.Flush()
+}
+
 // ReadBED reads Y-chromosome regions from a BED file
 // as described in http://genome.ucsc.edu/FAQ/FAQformat#format1
 func ReadBED(filename string) (BEDRegions, error) {
@@ -0,0 +1,268 @@
+package snp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chain converts Y-chromosome positions between genome builds,
+// for example from hg19 to hg38 or from hg38 to T2T-CHM13.
+// It is read from a UCSC chain file.
+type Chain struct {
+	// blocks are aligned regions sorted by their start
+	// in the source build. They do not overlap.
+	blocks []chainBlock
+}
+
+// chainBlock is an ungapped alignment between the source (target in
+// UCSC terms) and the destination build (query in UCSC terms).
+// Coordinates are zero based.
+type chainBlock struct {
+	tStart  int
+	qStart  int
+	size    int
+	qSize   int
+	reverse bool
+}
+
+// ReadChain reads the Y-chromosome alignments from a UCSC chain file
+// as described in https://genome.ucsc.edu/goldenPath/help/chain.html.
+// If chains overlap, the chain with the higher score is used.
+func ReadChain(filename string) (*Chain, error) {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer infile.Close()
+
+	type chain struct {
+		score  float64
+		blocks []chainBlock
+	}
+	var chains []chain
+	var current *chain
+	var tPos, qPos int
+	scanner := bufio.NewScanner(infile)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "chain" {
+			current = nil
+			if len(fields) < 12 {
+				return nil, errors.New(fmt.Sprintf("line %d, invalid chain header", lineNo))
+			}
+			if !isChrY(fields[2]) || !isChrY(fields[7]) {
+				continue
+			}
+			score, err1 := strconv.ParseFloat(fields[1], 64)
+			tStart, err2 := strconv.Atoi(fields[5])
+			qSize, err3 := strconv.Atoi(fields[8])
+			qStart, err4 := strconv.Atoi(fields[10])
+			if err1 != nil || err2 != nil || err3 != nil || err4 != nil || fields[4] != "+" {
+				return nil, errors.New(fmt.Sprintf("line %d, invalid chain header", lineNo))
+			}
+			chains = append(chains, chain{score: score})
+			current = &chains[len(chains)-1]
+			current.blocks = append(current.blocks, chainBlock{qSize: qSize, reverse: fields[9] == "-"})
+			tPos, qPos = tStart, qStart
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		// Alignment data line: size [dt dq]
+		size, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("line %d, invalid alignment data, %v", lineNo, err))
+		}
+		block := &current.blocks[len(current.blocks)-1]
+		block.tStart, block.qStart, block.size = tPos, qPos, size
+		tPos += size
+		qPos += size
+		if len(fields) >= 3 {
+			dt, err1 := strconv.Atoi(fields[1])
+			dq, err2 := strconv.Atoi(fields[2])
+			if err1 != nil || err2 != nil {
+				return nil, errors.New(fmt.Sprintf("line %d, invalid alignment data", lineNo))
+			}
+			tPos += dt
+			qPos += dq
+			current.blocks = append(current.blocks, chainBlock{qSize: block.qSize, reverse: block.reverse})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Add the blocks of the best chains first and skip
+	// blocks that overlap with blocks of better chains.
+	sort.SliceStable(chains, func(i, j int) bool { return chains[i].score > chains[j].score })
+	result := &Chain{}
+	for _, c := range chains {
+		for _, b := range c.blocks {
+			if b.size > 0 {
+				result.insert(b)
+			}
+		}
+	}
+	return result, nil
+}
+
+// isChrY tests if a chromosome name denotes the Y-chromosome.
+func isChrY(name string) bool {
+	return name == "chrY" || name == "Y"
+}
+
+// insert adds a block to the chain unless it overlaps an existing block.
+func (c *Chain) insert(b chainBlock) {
+	i := sort.Search(len(c.blocks), func(i int) bool { return c.blocks[i].tStart >= b.tStart })
+	if i > 0 && c.blocks[i-1].tStart+c.blocks[i-1].size > b.tStart {
+		return
+	}
+	if i < len(c.blocks) && c.blocks[i].tStart < b.tStart+b.size {
+		return
+	}
+	c.blocks = append(c.blocks, chainBlock{})
+	copy(c.blocks[i+1:], c.blocks[i:])
+	c.blocks[i] = b
+}
+
+// Map converts a one based position into the destination build.
+// reverse is true if the position is on the opposite strand
+// in the destination build.
+// ok is false if the position can not be mapped.
+func (c *Chain) Map(pos int) (newPos int, reverse bool, ok bool) {
+	p := pos - 1
+	i := sort.Search(len(c.blocks), func(i int) bool { return c.blocks[i].tStart > p }) - 1
+	if i < 0 || p >= c.blocks[i].tStart+c.blocks[i].size {
+		return 0, false, false
+	}
+	b := c.blocks[i]
+	q := b.qStart + p - b.tStart
+	if b.reverse {
+		q = b.qSize - 1 - q
+	}
+	return q + 1, b.reverse, true
+}
+
+// LiftSNPs converts SNPs into the destination build.
+// SNPs that are on the opposite strand in the destination build
+// get complemented alleles and are also reported in flipped.
+func (c *Chain) LiftSNPs(snps SNPs) (lifted, unmapped, flipped SNPs) {
+	for _, s := range snps {
+		pos, reverse, ok := c.Map(s.Pos)
+		if !ok {
+			unmapped = append(unmapped, s)
+			continue
+		}
+		l := SNP{Pos: pos, Ref: s.Ref, Alt: s.Alt}
+		if reverse {
+			l.Ref = complement(l.Ref)
+			l.Alt = complement(l.Alt)
+			flipped = append(flipped, l)
+		}
+		lifted = append(lifted, l)
+	}
+	return NewSNPs(lifted), unmapped, NewSNPs(flipped)
+}
+
+// LiftCSV converts CSV records into the destination build.
+// Records without a valid position are reported as unmapped.
+// Records that are on the opposite strand in the destination build
+// get complemented alleles and are also reported in flipped.
+func (c *Chain) LiftCSV(recs CSVRecords) (lifted, unmapped, flipped CSVRecords) {
+	for _, r := range recs {
+		p, err := strconv.Atoi(r.Pos)
+		if err != nil {
+			unmapped = append(unmapped, r)
+			continue
+		}
+		pos, reverse, ok := c.Map(p)
+		if !ok {
+			unmapped = append(unmapped, r)
+			continue
+		}
+		r.Pos = strconv.Itoa(pos)
+		if reverse {
+			r.Ref = complement(r.Ref)
+			r.Alt = complement(r.Alt)
+			flipped = append(flipped, r)
+		}
+		lifted = append(lifted, r)
+	}
+	return lifted, unmapped, flipped
+}
+
+// LiftBED converts BED regions into the destination build.
+// Parts of regions that can not be mapped are returned in unmapped.
+// The lifted regions are sorted and adjacent regions are merged.
+func (c *Chain) LiftBED(regions BEDRegions) (lifted, unmapped BEDRegions) {
+	for _, r := range regions {
+		start := r.Start
+		i := sort.Search(len(c.blocks), func(i int) bool { return c.blocks[i].tStart+c.blocks[i].size > r.Start })
+		for ; i < len(c.blocks) && c.blocks[i].tStart < r.End; i++ {
+			b := c.blocks[i]
+			s, e := r.Start, r.End
+			if b.tStart > s {
+				s = b.tStart
+			}
+			if b.tStart+b.size < e {
+				e = b.tStart + b.size
+			}
+			if s > start {
+				unmapped = append(unmapped, BEDRegion{Start: start, End: s})
+			}
+			qs := b.qStart + s - b.tStart
+			qe := qs + e - s
+			if b.reverse {
+				qs, qe = b.qSize-qe, b.qSize-qs
+			}
+			lifted = append(lifted, BEDRegion{Start: qs, End: qe})
+			start = e
+		}
+		if start < r.End {
+			unmapped = append(unmapped, BEDRegion{Start: start, End: r.End})
+		}
+	}
+	lifted.Normalize()
+	return lifted, unmapped
+}
+
+// complement returns the complementary allele on the opposite strand.
+func complement(allele string) string {
+	b := []byte(allele)
+	for i, c := range b {
+		switch c {
+		case 'A':
+			b[i] = 'T'
+		case 'T':
+			b[i] = 'A'
+		case 'C':
+			b[i] = 'G'
+		case 'G':
+			b[i] = 'C'
+		case 'a':
+			b[i] = 't'
+		case 't':
+			b[i] = 'a'
+		case 'c':
+			b[i] = 'g'
+		case 'g':
+			b[i] = 'c'
+		}
+	}
+	// Reverse the sequence for alleles with more than one base.
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
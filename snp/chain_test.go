@@ -0,0 +1,126 @@
+package snp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testChain contains a forward chain with a gap, a chain on the
+// reverse strand and a chain of another chromosome.
+const testChain = `chain 1000 chrY 1000 + 0 300 chrY 1000 + 10 310 1
+100 50 50
+150
+
+chain 500 chrY 1000 + 500 600 chrY 2000 - 100 200 2
+100
+
+chain 2000 chr1 1000 + 0 1000 chr1 1000 + 0 1000 3
+1000
+`
+
+// readTestChain writes testChain to a file and reads it.
+func readTestChain(t *testing.T) *Chain {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "test.chain")
+	if err := os.WriteFile(filename, []byte(testChain), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadChain(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestChainMap(t *testing.T) {
+	tests := []struct {
+		pos     int
+		newPos  int
+		reverse bool
+		ok      bool
+	}{
+		{1, 11, false, true},
+		{100, 110, false, true},
+		{101, 0, false, false},
+		{150, 0, false, false},
+		{151, 161, false, true},
+		{300, 310, false, true},
+		{301, 0, false, false},
+		{501, 1900, true, true},
+		{600, 1801, true, true},
+		{601, 0, false, false},
+	}
+	c := readTestChain(t)
+	for _, test := range tests {
+		newPos, reverse, ok := c.Map(test.pos)
+		if newPos != test.newPos || reverse != test.reverse || ok != test.ok {
+			t.Errorf("Map(%d) = %d, %v, %v, want %d, %v, %v",
+				test.pos, newPos, reverse, ok, test.newPos, test.reverse, test.ok)
+		}
+	}
+}
+
+func TestLiftSNPs(t *testing.T) {
+	c := readTestChain(t)
+	lifted, unmapped, flipped := c.LiftSNPs(SNPs{{1, "A", "G"}, {120, "C", "T"}, {501, "A", "C"}})
+	want := SNPs{{11, "A", "G"}, {1900, "T", "G"}}
+	if !equalSNPs(lifted, want) {
+		t.Errorf("lifted %v, want %v", lifted, want)
+	}
+	if !equalSNPs(unmapped, SNPs{{120, "C", "T"}}) {
+		t.Errorf("unmapped %v", unmapped)
+	}
+	if !equalSNPs(flipped, SNPs{{1900, "T", "G"}}) {
+		t.Errorf("flipped %v", flipped)
+	}
+}
+
+func TestLiftBED(t *testing.T) {
+	tests := []struct {
+		name     string
+		regions  BEDRegions
+		lifted   BEDRegions
+		unmapped BEDRegions
+	}{
+		{"forward", BEDRegions{{0, 50}}, BEDRegions{{10, 60}}, nil},
+		{"gap", BEDRegions{{50, 200}}, BEDRegions{{60, 110}, {160, 210}}, BEDRegions{{100, 150}}},
+		{"reverse", BEDRegions{{520, 540}}, BEDRegions{{1860, 1880}}, nil},
+		{"unmapped", BEDRegions{{700, 800}}, nil, BEDRegions{{700, 800}}},
+		{"both strands", BEDRegions{{250, 550}}, BEDRegions{{260, 310}, {1850, 1900}}, BEDRegions{{300, 500}}},
+	}
+	c := readTestChain(t)
+	for _, test := range tests {
+		lifted, unmapped := c.LiftBED(test.regions)
+		if !equalRegions(lifted, test.lifted) {
+			t.Errorf("%s: lifted %v, want %v", test.name, lifted, test.lifted)
+		}
+		if !equalRegions(unmapped, test.unmapped) {
+			t.Errorf("%s: unmapped %v, want %v", test.name, unmapped, test.unmapped)
+		}
+	}
+}
+
+func equalSNPs(a, b SNPs) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalRegions(a, b BEDRegions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -5,9 +5,16 @@ import (
 	"bytes"
 	"encoding/csv"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 )
 
+// NoPosition is written as position of SNPs whose position
+// is unknown, for example named SNPs in FTDNA files that
+// are not contained in the SNP data base.
+const NoPosition = "n/a"
+
 // CSVRecord represents a single line in a FTDNA CSV file.
 type CSVRecord struct {
 	Pos,
@@ -31,11 +38,76 @@ func (c *CSVRecord) String() string {
 	b.WriteString(",")
 	b.WriteString(c.Name)
 	b.WriteString(",\"")
-	b.WriteString(c.Comment)
+	b.WriteString(strings.Replace(c.Comment, "\"", "\"\"", -1))
 	b.WriteString("\"\r\n")
 	return b.String()
 }
 
+// Sort sorts the records by position, reference and alternative
+// allele and name. Records without a numeric position come last.
+func (c CSVRecords) Sort() {
+	sort.SliceStable(c, func(i, j int) bool {
+		a, b := c[i], c[j]
+		posA, errA := strconv.Atoi(a.Pos)
+		posB, errB := strconv.Atoi(b.Pos)
+		switch {
+		case errA != nil || errB != nil:
+			if (errA == nil) != (errB == nil) {
+				return errA == nil
+			}
+			if a.Pos != b.Pos {
+				return a.Pos < b.Pos
+			}
+		case posA != posB:
+			return posA < posB
+		}
+		if a.Ref != b.Ref {
+			return a.Ref < b.Ref
+		}
+		if a.Alt != b.Alt {
+			return a.Alt < b.Alt
+		}
+		return a.Name < b.Name
+	})
+}
+
+// ReadCSVRecords reads CSV records from a file in the format
+// that is written by CSVRecords.WriteCSV:
+// Pos, Ref, Alt, Name, Comment.
+// The columns Name and Comment are optional.
+func ReadCSVRecords(filename string) (CSVRecords, error) {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer infile.Close()
+
+	// Read all CSV records from file.
+	csvReader := csv.NewReader(infile)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CSVRecord, 0, len(records))
+	for _, fields := range records {
+		if len(fields) < 3 {
+			continue
+		}
+		rec := CSVRecord{Pos: fields[0], Ref: fields[1], Alt: fields[2]}
+		if len(fields) > 3 {
+			rec.Name = fields[3]
+		}
+		if len(fields) > 4 {
+			rec.Comment = fields[4]
+		}
+		result = append(result, rec)
+	}
+	return result, nil
+}
+
 // ReadFTDNAcsv reads CSV records from a FTDNA encoded CSV file.
 // If mutationsOnly == true, only true mutations are included in the result.
 // If novelsOnly == true, only novel variants are reported.
@@ -100,14 +172,14 @@ func ftdnaFieldsToCSVRecord(fields []string, mutationsOnly bool, novelsOnly bool
 	if err == nil {
 		rec.Pos = fields[pos]
 	} else {
-		rec.Pos = "n/a"
+		rec.Pos = NoPosition
 	}
 
 	if db != nil {
 		snpEntry, found := db.EntryByName(fields[name])
 		if found {
-			rec.Comment = snpEntry.Comment
-			if rec.Pos == "n/a" {
+			rec.Comment = snpEntry.Description()
+			if rec.Pos == NoPosition {
 				rec.Pos = strconv.Itoa(snpEntry.Key.Pos)
 			}
 		}
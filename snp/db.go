@@ -3,33 +3,76 @@ package snp
 import (
 	"encoding/csv"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // DB is an SNP data base.
 type DB struct {
-	snpRecords map[SNP]*DBRecord
-	snpNames   map[string]*DBRecord
+	records      []*DBRecord
+	snpRecords   map[SNP]*DBRecord
+	snpNames     map[string]*DBRecord
+	snpPositions map[int][]*DBRecord
+	// duplicateNames contains the records that were replaced in
+	// snpNames by later records with the same name.
+	duplicateNames map[string][]*DBRecord
+	// foldedNames maps lower case names to records.
+	// It is created when needed.
+	foldedNames map[string]*DBRecord
 }
 
 // DBRecord is an entry in the SNP data base.
-// The entry is simplifies and does not contain all information
-// that is listed at ISOGG.
+// Key contains the ancestral allele as Ref and the
+// derived allele as Alt.
 type DBRecord struct {
-	Key     SNP
-	Name    string
-	Comment string
+	Key  SNP
+	Name string
+	// Haplogroup is the ISOGG haplogroup.
+	Haplogroup string
+	// YCCHaplogroup is the haplogroup in YCC nomenclature.
+	YCCHaplogroup string
+	// Mutation describes the mutation, for example "C to T".
+	Mutation string
+	// Reference is the citation of the publication.
+	Reference string
+	Comment   string
+	// Source is the name of the data base source that
+	// supplied the record.
+	Source string
+	// FieldSources contains the names of the sources for fields
+	// that were supplied by a different source than the record itself.
+	// The map is indexed by field name.
+	FieldSources map[string]string
 }
 
 func NewDB() *DB {
-	return &DB{snpRecords: make(map[SNP]*DBRecord), snpNames: make(map[string]*DBRecord)}
+	return &DB{
+		snpRecords:     make(map[SNP]*DBRecord),
+		snpNames:       make(map[string]*DBRecord),
+		snpPositions:   make(map[int][]*DBRecord),
+		duplicateNames: make(map[string][]*DBRecord)}
 }
 
 func (db *DB) Add(entry DBRecord) {
-	db.snpRecords[entry.Key] = &entry
+	db.add(&entry, true)
+}
+
+// add adds a record to the data base. If byKey is false, the
+// record does not replace an existing entry for the same SNP.
+func (db *DB) add(entry *DBRecord, byKey bool) {
+	db.records = append(db.records, entry)
+	if _, exists := db.snpRecords[entry.Key]; byKey || !exists {
+		db.snpRecords[entry.Key] = entry
+	}
 	if entry.Name != "" {
-		db.snpNames[entry.Name] = &entry
+		if previous, exists := db.snpNames[entry.Name]; exists {
+			db.duplicateNames[entry.Name] = append(db.duplicateNames[entry.Name], previous)
+		}
+		db.snpNames[entry.Name] = entry
 	}
+	db.snpPositions[entry.Key.Pos] = append(db.snpPositions[entry.Key.Pos], entry)
+	db.foldedNames = nil
 }
 
 // ReadISOGGcsv reads an ISOGG CSV file and adds the SNPs
@@ -65,11 +108,15 @@ func (db *DB) ReadISOGGcsv(filename string) error {
 // http://ybrowse.org/gbrowse2/gff/
 func newDBRecord(fields []string) (entry DBRecord, exists bool) {
 	const (
-		pos     = 3
-		name    = 8
-		ref     = 10
-		alt     = 11
-		comment = 18
+		pos        = 3
+		name       = 8
+		ref        = 10
+		alt        = 11
+		ycc        = 12
+		haplogroup = 13
+		mutation   = 14
+		reference  = 17
+		comment    = 18
 	)
 	if len(fields) < 19 {
 		return entry, false
@@ -80,11 +127,52 @@ func newDBRecord(fields []string) (entry DBRecord, exists bool) {
 	}
 	entry.Key = SNP{Pos: int(snpPos), Ref: fields[ref], Alt: fields[alt]}
 	entry.Name = fields[name]
+	entry.Haplogroup = isoggValue(fields[haplogroup])
+	entry.YCCHaplogroup = isoggValue(fields[ycc])
+	entry.Mutation = isoggValue(fields[mutation])
+	entry.Reference = isoggValue(fields[reference])
 	entry.Comment = fields[comment]
 	exists = true
 	return
 }
 
+// isoggValue returns the value of an ISOGG CSV field.
+// Empty fields are marked with a dot in ISOGG files.
+func isoggValue(field string) string {
+	if field == "." {
+		return ""
+	}
+	return field
+}
+
+// Description returns a short text containing the haplogroup
+// assignment, mutation, reference and comment of the record.
+func (r *DBRecord) Description() string {
+	var parts []string
+	if r.Haplogroup != "" {
+		parts = append(parts, r.Haplogroup)
+	}
+	if r.YCCHaplogroup != "" {
+		parts = append(parts, "YCC "+r.YCCHaplogroup)
+	}
+	if r.Mutation != "" {
+		parts = append(parts, r.Mutation)
+	}
+	if r.Reference != "" {
+		parts = append(parts, r.Reference)
+	}
+	if r.Comment != "" {
+		parts = append(parts, r.Comment)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Records returns all entries of the data base in the order
+// in which they were added.
+func (db *DB) Records() []*DBRecord {
+	return db.records
+}
+
 func (db *DB) EntryByName(name string) (snp *DBRecord, exists bool) {
 	snp, exists = db.snpNames[name]
 	return
@@ -95,8 +183,165 @@ func (db *DB) EntryByKey(snp SNP) (entry *DBRecord, exists bool) {
 	return
 }
 
-/*
-func (db *DB) EntryByPosition(position int) (snps []DBRecord, exists bool) {
+// Match is the result of a strand aware data base lookup.
+type Match struct {
+	Record *DBRecord
+	// Flipped is true if the alleles only match on the opposite strand.
+	Flipped bool
+	// Ambiguous is true for A/T and C/G SNPs. Their strand can not be
+	// determined from the alleles, so they may match on the wrong strand.
+	Ambiguous bool
+}
+
+// MatchByKey looks up an SNP like EntryByKey. If there is no exact match,
+// the SNP is compared to the complemented alleles of the entries at
+// the same position.
+func (db *DB) MatchByKey(snp SNP) (m Match, exists bool) {
+	m.Ambiguous = isStrandAmbiguous(snp.Ref, snp.Alt)
+	m.Record, exists = db.snpRecords[snp]
+	if exists {
+		return m, true
+	}
+	ref, alt := complement(snp.Ref), complement(snp.Alt)
+	for _, r := range db.snpPositions[snp.Pos] {
+		if r.Key.Ref == ref && r.Key.Alt == alt {
+			m.Record = r
+			m.Flipped = true
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// isStrandAmbiguous tests if the alleles of an SNP are complementary
+// (A/T or C/G), so that both strands have the same alleles.
+func isStrandAmbiguous(ref, alt string) bool {
+	return len(ref) == 1 && ref != alt && complement(ref) == alt
+}
+
+// EntryByPosition returns all entries at the given position.
+func (db *DB) EntryByPosition(position int) (snps []*DBRecord, exists bool) {
+	snps, exists = db.snpPositions[position]
+	return
+}
+
+// EntriesInRange returns all entries with positions from start
+// to end, including start and end, sorted by position.
+func (db *DB) EntriesInRange(start, end int) []*DBRecord {
+	return db.filter(func(r *DBRecord) bool {
+		return r.Key.Pos >= start && r.Key.Pos <= end
+	})
+}
+
+// EntriesByHaplogroup returns all entries that belong to a haplogroup.
+// The comparison is case insensitive. If label ends with an asterisk,
+// all subclades are included, for example R1b1a1b*.
+func (db *DB) EntriesByHaplogroup(label string) []*DBRecord {
+	label = strings.ToLower(label)
+	if strings.HasSuffix(label, "*") {
+		prefix := strings.TrimSuffix(label, "*")
+		return db.filter(func(r *DBRecord) bool {
+			return strings.HasPrefix(strings.ToLower(r.Haplogroup), prefix)
+		})
+	}
+	return db.filter(func(r *DBRecord) bool {
+		return strings.ToLower(r.Haplogroup) == label
+	})
+}
+
+// EntriesByComment returns all entries whose comment contains substr.
+// The comparison is case insensitive.
+func (db *DB) EntriesByComment(substr string) []*DBRecord {
+	substr = strings.ToLower(substr)
+	return db.filter(func(r *DBRecord) bool {
+		return strings.Contains(strings.ToLower(r.Comment), substr)
+	})
+}
+
+// EntriesByNameOrAlias returns the entry for a name and all entries
+// for the same SNP under other names. If there is no exact match,
+// the name is compared case insensitively.
+func (db *DB) EntriesByNameOrAlias(name string) []*DBRecord {
+	entry, exists := db.EntryByName(name)
+	if !exists {
+		entry, exists = db.entryByFoldedName(name)
+	}
+	if !exists {
+		return nil
+	}
+	result := []*DBRecord{entry}
+	for _, r := range db.snpPositions[entry.Key.Pos] {
+		if r != entry && r.Key == entry.Key {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// EntriesByName returns all entries with the given name.
+// There may be several entries, for example for SNPs in palindromic
+// regions. If there is no exact match, the name is compared
+// case insensitively.
+func (db *DB) EntriesByName(name string) []*DBRecord {
+	entry, exists := db.EntryByName(name)
+	if !exists {
+		entry, exists = db.entryByFoldedName(name)
+		if !exists {
+			return nil
+		}
+		name = entry.Name
+	}
+	result := []*DBRecord{entry}
+	for _, r := range db.duplicateNames[name] {
+		if r.Key != entry.Key {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// entryByFoldedName looks up a name case insensitively.
+func (db *DB) entryByFoldedName(name string) (entry *DBRecord, exists bool) {
+	if db.foldedNames == nil {
+		db.foldedNames = make(map[string]*DBRecord, len(db.snpNames))
+		for n, r := range db.snpNames {
+			db.foldedNames[strings.ToLower(n)] = r
+		}
+	}
+	entry, exists = db.foldedNames[strings.ToLower(name)]
+	return
+}
+
+// Aliases returns the names of all other entries for the same SNP.
+func (db *DB) Aliases(entry *DBRecord) []string {
+	var aliases []string
+	for _, r := range db.snpPositions[entry.Key.Pos] {
+		if r.Key == entry.Key && r.Name != "" && r.Name != entry.Name {
+			aliases = append(aliases, r.Name)
+		}
+	}
+	return aliases
+}
 
+// filter returns all entries that satisfy the function accept,
+// sorted by position and name.
+func (db *DB) filter(accept func(r *DBRecord) bool) []*DBRecord {
+	var result []*DBRecord
+	for _, r := range db.records {
+		if accept(r) {
+			result = append(result, r)
+		}
+	}
+	SortDBRecords(result)
+	return result
+}
+
+// SortDBRecords sorts data base records by position and name.
+func SortDBRecords(recs []*DBRecord) {
+	sort.SliceStable(recs, func(i, j int) bool {
+		if recs[i].Key.Pos != recs[j].Key.Pos {
+			return recs[i].Key.Pos < recs[j].Key.Pos
+		}
+		return recs[i].Name < recs[j].Name
+	})
 }
-*/
@@ -0,0 +1,142 @@
+package snp
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// queryDB returns a data base for query tests.
+func queryDB() *DB {
+	db := NewDB()
+	for _, r := range []DBRecord{
+		{Key: SNP{Pos: 22739367, Ref: "C", Alt: "T"}, Name: "M269", Haplogroup: "R1b1a1b"},
+		{Key: SNP{Pos: 22739367, Ref: "C", Alt: "T"}, Name: "S3", Haplogroup: "R1b1a1b"},
+		{Key: SNP{Pos: 2887824, Ref: "C", Alt: "T"}, Name: "P312", Haplogroup: "R1b1a1b1a1a2"},
+		{Key: SNP{Pos: 20577481, Ref: "C", Alt: "G"}, Name: "L21", Haplogroup: "R1b1a1b1a1a2c", Comment: "Also known as S145"},
+		{Key: SNP{Pos: 15654428, Ref: "G", Alt: "A"}, Name: "DF13", Haplogroup: "R1b1a1b1a1a2c1"},
+		{Key: SNP{Pos: 7000000, Ref: "A", Alt: "T"}, Name: "Z1", Haplogroup: "R1b", Comment: "palindromic"},
+		{Key: SNP{Pos: 9000000, Ref: "G", Alt: "C"}, Name: "Z1", Haplogroup: "R1b", Comment: "Palindromic"},
+	} {
+		db.Add(r)
+	}
+	return db
+}
+
+// recordNames returns the names and positions of records.
+func recordNames(recs []*DBRecord) string {
+	var names []string
+	for _, r := range recs {
+		names = append(names, r.Name+"@"+strconv.Itoa(r.Key.Pos))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestQuery(t *testing.T) {
+	db := queryDB()
+	tests := []struct {
+		name string
+		recs []*DBRecord
+		want string
+	}{
+		{"name", db.EntriesByNameOrAlias("L21"), "L21@20577481"},
+		{"name with alias", db.EntriesByNameOrAlias("M269"), "M269@22739367,S3@22739367"},
+		{"alias", db.EntriesByNameOrAlias("S3"), "S3@22739367,M269@22739367"},
+		{"case insensitive name", db.EntriesByNameOrAlias("df13"), "DF13@15654428"},
+		{"unknown name", db.EntriesByNameOrAlias("X1"), ""},
+		{"palindromic name", db.EntriesByName("Z1"), "Z1@9000000,Z1@7000000"},
+		{"range", db.EntriesInRange(7000000, 20577481), "Z1@7000000,Z1@9000000,DF13@15654428,L21@20577481"},
+		{"single position", db.EntriesInRange(22739367, 22739367), "M269@22739367,S3@22739367"},
+		{"empty range", db.EntriesInRange(1, 100), ""},
+		{"haplogroup", db.EntriesByHaplogroup("r1b1a1b1a1a2c"), "L21@20577481"},
+		{"subclades", db.EntriesByHaplogroup("R1b1a1b1a1a2*"), "P312@2887824,DF13@15654428,L21@20577481"},
+		{"comment", db.EntriesByComment("PALINDROMIC"), "Z1@7000000,Z1@9000000"},
+	}
+	for _, test := range tests {
+		if got := recordNames(test.recs); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+	m269, _ := db.EntryByName("M269")
+	if aliases := db.Aliases(m269); len(aliases) != 1 || aliases[0] != "S3" {
+		t.Errorf("got aliases %v for M269, want S3", aliases)
+	}
+}
+
+func TestReadISOGGcsv(t *testing.T) {
+	db := NewDB()
+	if err := db.ReadISOGGcsv(writeTestISOGG(t)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		record      DBRecord
+		description string
+	}{
+		{
+			name: "M269",
+			record: DBRecord{Key: SNP{Pos: 22739367, Ref: "C", Alt: "T"}, Name: "M269", Haplogroup: "R1b1a1b",
+				YCCHaplogroup: "R1b1a2", Mutation: "C to T", Reference: "Cruciani 2010"},
+			description: "R1b1a1b; YCC R1b1a2; C to T; Cruciani 2010",
+		},
+		{
+			name: "L21",
+			record: DBRecord{Key: SNP{Pos: 20577481, Ref: "C", Alt: "G"}, Name: "L21", Haplogroup: "R1b1a1b1a1a2c1",
+				Mutation: "C to G", Comment: "also S145"},
+			description: "R1b1a1b1a1a2c1; C to G; also S145",
+		},
+	}
+	for _, test := range tests {
+		r, exists := db.EntryByName(test.name)
+		if !exists {
+			t.Errorf("%s: not found", test.name)
+			continue
+		}
+		equalRecords(t, []*DBRecord{r}, []*DBRecord{&test.record})
+		if got := r.Description(); got != test.description {
+			t.Errorf("%s: got description %q, want %q", test.name, got, test.description)
+		}
+	}
+	if got := len(db.Records()); got != 4 {
+		t.Errorf("got %d records, want 4", got)
+	}
+}
+
+func TestMatchByKey(t *testing.T) {
+	db := NewDB()
+	db.Add(DBRecord{Key: SNP{Pos: 100, Ref: "C", Alt: "T"}, Name: "M269"})
+	db.Add(DBRecord{Key: SNP{Pos: 200, Ref: "A", Alt: "T"}, Name: "Z1"})
+	db.Add(DBRecord{Key: SNP{Pos: 300, Ref: "AC", Alt: "A"}, Name: "D1"})
+	tests := []struct {
+		name      string
+		snp       SNP
+		exists    bool
+		record    string
+		flipped   bool
+		ambiguous bool
+	}{
+		{"exact", SNP{Pos: 100, Ref: "C", Alt: "T"}, true, "M269", false, false},
+		{"opposite strand", SNP{Pos: 100, Ref: "G", Alt: "A"}, true, "M269", true, false},
+		{"reversed alleles", SNP{Pos: 100, Ref: "T", Alt: "C"}, false, "", false, false},
+		{"ambiguous exact", SNP{Pos: 200, Ref: "A", Alt: "T"}, true, "Z1", false, true},
+		{"ambiguous reversed", SNP{Pos: 200, Ref: "T", Alt: "A"}, true, "Z1", true, true},
+		{"indel opposite strand", SNP{Pos: 300, Ref: "GT", Alt: "T"}, true, "D1", true, false},
+		{"other position", SNP{Pos: 101, Ref: "C", Alt: "T"}, false, "", false, false},
+	}
+	for _, test := range tests {
+		m, exists := db.MatchByKey(test.snp)
+		if exists != test.exists {
+			t.Errorf("%s: got exists=%v, want %v", test.name, exists, test.exists)
+			continue
+		}
+		if m.Ambiguous != test.ambiguous {
+			t.Errorf("%s: got ambiguous=%v, want %v", test.name, m.Ambiguous, test.ambiguous)
+		}
+		if !exists {
+			continue
+		}
+		if m.Record.Name != test.record || m.Flipped != test.flipped {
+			t.Errorf("%s: got %s flipped=%v, want %s flipped=%v", test.name, m.Record.Name, m.Flipped, test.record, test.flipped)
+		}
+	}
+}
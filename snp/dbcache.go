@@ -0,0 +1,235 @@
+package snp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CacheExt is the extension that is appended to the name of
+// an ISOGG CSV file to get the name of its binary cache file.
+const CacheExt = ".cache"
+
+// Binary cache file format:
+//
+//	magic    4 bytes "PSDB"
+//	version  uint32, little endian
+//	checksum 32 bytes, SHA-256 of the source CSV file
+//	count    uvarint, number of records
+//	records  count times: Pos as uvarint followed by the strings
+//	         Ref, Alt, Name, Haplogroup, YCCHaplogroup, Mutation, Reference
+//	         and Comment, each as uvarint length and bytes.
+const (
+	cacheMagic   = "PSDB"
+	cacheVersion = 3
+)
+
+// ReadISOGGcached reads an ISOGG CSV file like ReadISOGGcsv but
+// uses a binary cache file to speed up repeated reads.
+// The cache file is named like the CSV file with the extension
+// CacheExt appended. It is created automatically if it does not
+// exist or if it is outdated. Failing to write the cache file
+// is not considered an error.
+func (db *DB) ReadISOGGcached(filename string) error {
+	checksum, err := fileChecksum(filename)
+	if err != nil {
+		return err
+	}
+	cacheDB := NewDB()
+	err = cacheDB.readCache(filename+CacheExt, checksum)
+	if err != nil {
+		cacheDB = NewDB()
+		err = cacheDB.ReadISOGGcsv(filename)
+		if err != nil {
+			return err
+		}
+		cacheDB.WriteCache(filename+CacheExt, checksum)
+	}
+	for _, rec := range cacheDB.records {
+		db.Add(*rec)
+	}
+	return nil
+}
+
+// WriteCache writes all records of the data base to a binary cache file.
+// checksum is the SHA-256 checksum of the source file.
+// The cache is written to a temporary file that is renamed afterwards,
+// so that other processes never read a partially written cache.
+func (db *DB) WriteCache(filename string, checksum []byte) error {
+	if len(checksum) != sha256.Size {
+		return errors.New("invalid checksum size")
+	}
+	outfile, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	err = db.writeCache(outfile, checksum)
+	if err == nil {
+		err = outfile.Chmod(0644)
+	}
+	if closeErr := outfile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(outfile.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(outfile.Name())
+	}
+	return err
+}
+
+// writeCache writes the cache data to w.
+func (db *DB) writeCache(outfile io.Writer, checksum []byte) error {
+	w := bufio.NewWriter(outfile)
+	var header [8]byte
+	copy(header[:4], cacheMagic)
+	binary.LittleEndian.PutUint32(header[4:], cacheVersion)
+	w.Write(header[:])
+	w.Write(checksum)
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(x uint64) {
+		n := binary.PutUvarint(buf, x)
+		w.Write(buf[:n])
+	}
+	putString := func(s string) {
+		putUvarint(uint64(len(s)))
+		w.WriteString(s)
+	}
+	putUvarint(uint64(len(db.records)))
+	for _, rec := range db.records {
+		putUvarint(uint64(rec.Key.Pos))
+		putString(rec.Key.Ref)
+		putString(rec.Key.Alt)
+		putString(rec.Name)
+		putString(rec.Haplogroup)
+		putString(rec.YCCHaplogroup)
+		putString(rec.Mutation)
+		putString(rec.Reference)
+		putString(rec.Comment)
+	}
+	return w.Flush()
+}
+
+// readCache reads records from a binary cache file and adds
+// them to the data base. An error is returned if the file does
+// not exist, has a different version or if its checksum differs
+// from checksum.
+func (db *DB) readCache(filename string, checksum []byte) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	headerLen := 8 + sha256.Size
+	if len(data) < headerLen || string(data[:4]) != cacheMagic {
+		return errors.New("not an SNP data base cache file")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:8]); version != cacheVersion {
+		return errors.New(fmt.Sprintf("unsupported cache version %d", version))
+	}
+	if !bytes.Equal(data[8:headerLen], checksum) {
+		return errors.New("cache file is outdated")
+	}
+
+	// Convert all data into a single string, so that the
+	// record strings share its memory.
+	d := &cacheDecoder{data: string(data[headerLen:])}
+	// Each record takes at least minRecordLen bytes, which limits
+	// the count of a corrupt file.
+	const minRecordLen = 9
+	count := d.uvarint()
+	if d.err == nil && count > uint64(len(d.data)-d.pos)/minRecordLen {
+		return errors.New("corrupt cache file")
+	}
+	records := make([]DBRecord, 0, count)
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		var rec DBRecord
+		rec.Key.Pos = int(d.uvarint())
+		rec.Key.Ref = d.string()
+		rec.Key.Alt = d.string()
+		rec.Name = d.string()
+		rec.Haplogroup = d.string()
+		rec.YCCHaplogroup = d.string()
+		rec.Mutation = d.string()
+		rec.Reference = d.string()
+		rec.Comment = d.string()
+		records = append(records, rec)
+	}
+	if d.err == nil && d.pos != len(d.data) {
+		d.err = errors.New("corrupt cache file")
+	}
+	if d.err != nil {
+		return d.err
+	}
+	for _, rec := range records {
+		db.Add(rec)
+	}
+	return nil
+}
+
+// cacheDecoder decodes values from binary cache data.
+// After the first error all further calls return zero values.
+type cacheDecoder struct {
+	data string
+	pos  int
+	err  error
+}
+
+func (d *cacheDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var x uint64
+	var s uint
+	for i := 0; d.pos < len(d.data); i++ {
+		b := d.data[d.pos]
+		d.pos++
+		if b < 0x80 {
+			if i >= binary.MaxVarintLen64 {
+				break
+			}
+			return x | uint64(b)<<s
+		}
+		x |= uint64(b&0x7f) << s
+		s += 7
+	}
+	d.err = errors.New("corrupt cache file")
+	return 0
+}
+
+func (d *cacheDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if uint64(len(d.data)-d.pos) < n {
+		d.err = errors.New("corrupt cache file")
+		return ""
+	}
+	s := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return s
+}
+
+// fileChecksum calculates the SHA-256 checksum of a file.
+func fileChecksum(filename string) ([]byte, error) {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer infile.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, infile)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
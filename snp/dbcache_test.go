@@ -0,0 +1,126 @@
+package snp
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testISOGG = `seqid,source,type,start,end,score,strand,phase,Name,ID,allele_anc,allele_der,YCC_haplogroup,ISOGG_haplogroup,mutation,count_tested,count_derived,ref,comment
+chrY,point,snp,22739367,22739367,.,+,.,M269,M269,C,T,R1b1a2,R1b1a1b,C to T,.,.,Cruciani 2010,
+chrY,point,snp,20577481,20577481,.,+,.,L21,L21,C,G,.,R1b1a1b1a1a2c1,C to G,.,.,,"also S145"
+chrY,point,snp,7000000,7000000,.,+,.,Z1,Z1,A,T,,R1b,A to T,.,.,,
+chrY,point,snp,9000000,9000000,.,+,.,Z1,Z1,G,C,,R1b,G to C,.,.,,
+`
+
+// writeTestISOGG writes testISOGG to a file in a temporary directory.
+func writeTestISOGG(t *testing.T) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "snps.csv")
+	if err := os.WriteFile(filename, []byte(testISOGG), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// equalRecords compares the fields of data base records.
+func equalRecords(t *testing.T, got, want []*DBRecord) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range got {
+		g, w := *got[i], *want[i]
+		if g.Key != w.Key || g.Name != w.Name || g.Haplogroup != w.Haplogroup ||
+			g.YCCHaplogroup != w.YCCHaplogroup || g.Mutation != w.Mutation ||
+			g.Reference != w.Reference || g.Comment != w.Comment {
+			t.Errorf("record %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	filename := writeTestISOGG(t)
+	db := NewDB()
+	if err := db.ReadISOGGcsv(filename); err != nil {
+		t.Fatal(err)
+	}
+	checksum := sha256.Sum256([]byte(testISOGG))
+	cacheName := filename + CacheExt
+	if err := db.WriteCache(cacheName, checksum[:]); err != nil {
+		t.Fatal(err)
+	}
+	cached := NewDB()
+	if err := cached.readCache(cacheName, checksum[:]); err != nil {
+		t.Fatal(err)
+	}
+	equalRecords(t, cached.Records(), db.Records())
+
+	// An outdated cache is rejected.
+	other := sha256.Sum256([]byte("other"))
+	if err := NewDB().readCache(cacheName, other[:]); err == nil {
+		t.Error("outdated cache accepted")
+	}
+
+	// ReadISOGGcached creates and uses the cache.
+	os.Remove(cacheName)
+	for i := 0; i < 2; i++ {
+		cached = NewDB()
+		if err := cached.ReadISOGGcached(filename); err != nil {
+			t.Fatal(err)
+		}
+		equalRecords(t, cached.Records(), db.Records())
+	}
+	if _, err := os.Stat(cacheName); err != nil {
+		t.Errorf("cache not written, %v", err)
+	}
+}
+
+func TestCorruptCache(t *testing.T) {
+	filename := writeTestISOGG(t)
+	db := NewDB()
+	if err := db.ReadISOGGcsv(filename); err != nil {
+		t.Fatal(err)
+	}
+	checksum := sha256.Sum256([]byte(testISOGG))
+	cacheName := filename + CacheExt
+	if err := db.WriteCache(cacheName, checksum[:]); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cacheName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := 8 + sha256.Size
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"header only", data[:headerLen]},
+		{"truncated", data[:len(data)-5]},
+		{"huge count", append(append([]byte(nil), data[:headerLen]...), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f)},
+		{"overlong varint", append(append([]byte(nil), data[:headerLen]...), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)},
+		{"trailing data", append(append([]byte(nil), data...), 0)},
+	}
+	for _, test := range tests {
+		if err := os.WriteFile(cacheName, test.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewDB().readCache(cacheName, checksum[:]); err == nil {
+			t.Errorf("%s: corrupt cache accepted", test.name)
+		}
+
+		// The CSV file is read instead and the cache is replaced.
+		cached := NewDB()
+		if err := cached.ReadISOGGcached(filename); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		equalRecords(t, cached.Records(), db.Records())
+		if err := NewDB().readCache(cacheName, checksum[:]); err != nil {
+			t.Errorf("%s: cache not replaced, %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package snp
+
+import (
+	"sort"
+)
+
+// DBDiff contains the differences between two versions of an SNP data base.
+type DBDiff struct {
+	// Added are records of the new version that do not exist in the old one.
+	Added []*DBRecord
+	// Removed are records of the old version that do not exist in the new one.
+	Removed []*DBRecord
+	// Renamed are SNPs that have a different name in the new version.
+	Renamed []DBChange
+	// Moved are SNPs that have a different position or different
+	// alleles in the new version.
+	Moved []DBChange
+}
+
+// DBChange is an SNP that changed between two data base versions.
+type DBChange struct {
+	Old *DBRecord
+	New *DBRecord
+}
+
+// DiffDB compares two versions of an SNP data base.
+// Records are matched by name. Records without a name are matched
+// by SNP. A record whose name does not exist in the other version
+// is considered as renamed if the other version contains the same
+// SNP under a name that does not exist in the first version.
+func DiffDB(oldDB, newDB *DB) DBDiff {
+	var diff DBDiff
+	renamedTo := make(map[*DBRecord]bool)
+	for _, o := range oldDB.records {
+		if o.Name == "" {
+			if _, exists := newDB.snpRecords[o.Key]; !exists {
+				diff.Removed = append(diff.Removed, o)
+			}
+			continue
+		}
+		n, exists := newDB.snpNames[o.Name]
+		if exists {
+			if n.Key != o.Key && oldDB.snpNames[o.Name] == o {
+				diff.Moved = append(diff.Moved, DBChange{Old: o, New: n})
+			}
+			continue
+		}
+		renamed := false
+		for _, r := range newDB.snpPositions[o.Key.Pos] {
+			_, inOld := oldDB.snpNames[r.Name]
+			if r.Key == o.Key && r.Name != "" && !inOld && !renamedTo[r] {
+				diff.Renamed = append(diff.Renamed, DBChange{Old: o, New: r})
+				renamedTo[r] = true
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			diff.Removed = append(diff.Removed, o)
+		}
+	}
+	for _, n := range newDB.records {
+		if n.Name == "" {
+			if _, exists := oldDB.snpRecords[n.Key]; !exists {
+				diff.Added = append(diff.Added, n)
+			}
+			continue
+		}
+		if _, exists := oldDB.snpNames[n.Name]; !exists && !renamedTo[n] {
+			diff.Added = append(diff.Added, n)
+		}
+	}
+	SortDBRecords(diff.Added)
+	SortDBRecords(diff.Removed)
+	sortDBChanges(diff.Renamed)
+	sortDBChanges(diff.Moved)
+	return diff
+}
+
+// sortDBChanges sorts changes by the position and name of the new record.
+func sortDBChanges(changes []DBChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i].New, changes[j].New
+		if a.Key.Pos != b.Key.Pos {
+			return a.Key.Pos < b.Key.Pos
+		}
+		return a.Name < b.Name
+	})
+}
+
+// AffectedPositions returns all old and new positions of changed SNPs.
+func (d *DBDiff) AffectedPositions() map[int]bool {
+	result := make(map[int]bool)
+	for _, r := range d.Added {
+		result[r.Key.Pos] = true
+	}
+	for _, r := range d.Removed {
+		result[r.Key.Pos] = true
+	}
+	for _, changes := range [][]DBChange{d.Renamed, d.Moved} {
+		for _, c := range changes {
+			result[c.Old.Key.Pos] = true
+			result[c.New.Key.Pos] = true
+		}
+	}
+	return result
+}
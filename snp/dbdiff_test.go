@@ -0,0 +1,133 @@
+package snp
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiffDB(t *testing.T) {
+	record := func(pos int, ref, alt, name string) DBRecord {
+		return DBRecord{Key: SNP{Pos: pos, Ref: ref, Alt: alt}, Name: name}
+	}
+	tests := []struct {
+		name                           string
+		old, new                       []DBRecord
+		added, removed, renamed, moved []string
+		affected                       []int
+	}{
+		{
+			name: "unchanged",
+			old:  []DBRecord{record(100, "C", "T", "M269")},
+			new:  []DBRecord{record(100, "C", "T", "M269")},
+		},
+		{
+			name:     "added and removed",
+			old:      []DBRecord{record(100, "C", "T", "M269"), record(200, "A", "G", "Y1")},
+			new:      []DBRecord{record(100, "C", "T", "M269"), record(300, "G", "A", "Y2")},
+			added:    []string{"Y2"},
+			removed:  []string{"Y1"},
+			affected: []int{200, 300},
+		},
+		{
+			name:     "renamed",
+			old:      []DBRecord{record(100, "C", "T", "FGC1")},
+			new:      []DBRecord{record(100, "C", "T", "L21")},
+			renamed:  []string{"FGC1->L21"},
+			affected: []int{100},
+		},
+		{
+			name:     "new name exists in old version",
+			old:      []DBRecord{record(100, "C", "T", "FGC1"), record(500, "C", "T", "L21")},
+			new:      []DBRecord{record(100, "C", "T", "L21")},
+			removed:  []string{"FGC1"},
+			moved:    []string{"L21->L21"},
+			affected: []int{100, 500},
+		},
+		{
+			name:     "moved",
+			old:      []DBRecord{record(100, "C", "T", "M269")},
+			new:      []DBRecord{record(150, "C", "T", "M269")},
+			moved:    []string{"M269->M269"},
+			affected: []int{100, 150},
+		},
+		{
+			name:     "different alleles",
+			old:      []DBRecord{record(100, "C", "T", "M269")},
+			new:      []DBRecord{record(100, "C", "A", "M269")},
+			moved:    []string{"M269->M269"},
+			affected: []int{100},
+		},
+		{
+			name:     "two names for one SNP",
+			old:      []DBRecord{record(100, "C", "T", "A1"), record(100, "C", "T", "A2")},
+			new:      []DBRecord{record(100, "C", "T", "B1")},
+			removed:  []string{"A2"},
+			renamed:  []string{"A1->B1"},
+			affected: []int{100},
+		},
+		{
+			name:     "unnamed records",
+			old:      []DBRecord{record(100, "C", "T", ""), record(200, "C", "T", "")},
+			new:      []DBRecord{record(100, "C", "T", ""), record(300, "C", "T", "")},
+			added:    []string{"@300"},
+			removed:  []string{"@200"},
+			affected: []int{200, 300},
+		},
+	}
+	for _, test := range tests {
+		oldDB, newDB := NewDB(), NewDB()
+		for _, r := range test.old {
+			oldDB.Add(r)
+		}
+		for _, r := range test.new {
+			newDB.Add(r)
+		}
+		diff := DiffDB(oldDB, newDB)
+		compareStrings(t, test.name+" added", diffNames(diff.Added), test.added)
+		compareStrings(t, test.name+" removed", diffNames(diff.Removed), test.removed)
+		compareStrings(t, test.name+" renamed", changeNames(diff.Renamed), test.renamed)
+		compareStrings(t, test.name+" moved", changeNames(diff.Moved), test.moved)
+		affected := diff.AffectedPositions()
+		if len(affected) != len(test.affected) {
+			t.Errorf("%s: got affected positions %v, want %v", test.name, affected, test.affected)
+			continue
+		}
+		for _, pos := range test.affected {
+			if !affected[pos] {
+				t.Errorf("%s: position %d not affected", test.name, pos)
+			}
+		}
+	}
+}
+
+// diffNames returns the names of records or, for records
+// without a name, their positions prefixed by @.
+func diffNames(recs []*DBRecord) []string {
+	var names []string
+	for _, r := range recs {
+		if r.Name == "" {
+			names = append(names, "@"+strconv.Itoa(r.Key.Pos))
+		} else {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// changeNames returns the changes as old name->new name.
+func changeNames(changes []DBChange) []string {
+	var names []string
+	for _, c := range changes {
+		names = append(names, c.Old.Name+"->"+c.New.Name)
+	}
+	return names
+}
+
+// compareStrings compares two lists of strings.
+func compareStrings(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("%s: got %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,265 @@
+package snp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Formats of SNP data base sources.
+const (
+	// FormatISOGG is the ISOGG CSV format from http://ybrowse.org/gbrowse2/gff/.
+	FormatISOGG = "isogg"
+	// FormatCSV is the CSV format written by CSVRecords.WriteCSV.
+	// It is used for private SNP lists.
+	FormatCSV = "csv"
+	// FormatYFull is the YTree JSON export of YFull. It contains
+	// SNP names but no positions, so it only supplies the haplogroups
+	// of SNPs from sources that are listed before it.
+	FormatYFull = "yfull"
+)
+
+// Kinds of data base conflicts.
+const (
+	NamePositionConflict = "same name, different position"
+	PositionNameConflict = "same position, different name"
+)
+
+// DBSource describes a source for the SNP data base.
+type DBSource struct {
+	// Name is used to record the provenance of data base records.
+	Name     string
+	Format   string
+	Filename string
+}
+
+// DBConflict is a conflict between two data base records.
+type DBConflict struct {
+	Kind string
+	// Existing is the record that is contained in the data base.
+	Existing *DBRecord
+	// Record is the record that caused the conflict.
+	Record *DBRecord
+}
+
+// String returns a DBConflict as a single line of text
+// including the line ending CRLF.
+func (c *DBConflict) String() string {
+	var b bytes.Buffer
+	b.WriteString(c.Kind)
+	b.WriteString(": ")
+	b.WriteString(recordSummary(c.Existing))
+	b.WriteString(" vs ")
+	b.WriteString(recordSummary(c.Record))
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// recordSummary returns a short description of a data base record
+// including its source.
+func recordSummary(r *DBRecord) string {
+	return fmt.Sprintf("%s %d %s->%s (%s)", r.Name, r.Key.Pos, r.Key.Ref, r.Key.Alt, r.Source)
+}
+
+// ReadDBSources reads several SNP data base sources and merges them
+// into a single data base.
+// The sources must be ordered by priority, highest priority first.
+// If cached is true, ISOGG files are read using a binary cache.
+func ReadDBSources(sources []DBSource, cached bool) (*DB, []DBConflict, error) {
+	db := NewDB()
+	var conflicts []DBConflict
+	for _, source := range sources {
+		sourceDB := NewDB()
+		var err error
+		switch source.Format {
+		case FormatISOGG:
+			if cached {
+				err = sourceDB.ReadISOGGcached(source.Filename)
+			} else {
+				err = sourceDB.ReadISOGGcsv(source.Filename)
+			}
+		case FormatCSV:
+			err = sourceDB.ReadCSV(source.Filename)
+		case FormatYFull:
+			err = sourceDB.ReadYFull(source.Filename)
+		default:
+			err = errors.New(fmt.Sprintf("unknown data base format %s", source.Format))
+		}
+		if err != nil {
+			return db, conflicts, errors.New(fmt.Sprintf("reading %s, %v", source.Filename, err))
+		}
+		for _, rec := range sourceDB.records {
+			rec.Source = source.Name
+		}
+		conflicts = append(conflicts, db.Merge(sourceDB)...)
+	}
+	return db, conflicts, nil
+}
+
+// ReadCSV reads SNPs from a CSV file in the format written by
+// CSVRecords.WriteCSV and adds them to the data base.
+// Records without a valid position are skipped.
+func (db *DB) ReadCSV(filename string) error {
+	recs, err := ReadCSVRecords(filename)
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		pos, err := strconv.Atoi(r.Pos)
+		if err != nil {
+			continue
+		}
+		db.Add(DBRecord{
+			Key:     SNP{Pos: pos, Ref: r.Ref, Alt: r.Alt},
+			Name:    r.Name,
+			Comment: r.Comment})
+	}
+	return nil
+}
+
+// yfullBranch is a branch in the YFull YTree JSON export.
+type yfullBranch struct {
+	ID       string        `json:"id"`
+	SNPs     string        `json:"snps"`
+	Children []yfullBranch `json:"children"`
+}
+
+// ReadYFull reads the SNP names of a YFull YTree JSON export and
+// adds them to the data base. The id of the branch becomes the
+// haplogroup of its SNPs. Synonyms like L21/S145 are added as
+// separate records. The records have no position.
+func (db *DB) ReadYFull(filename string) error {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer infile.Close()
+	var root yfullBranch
+	if err := json.NewDecoder(infile).Decode(&root); err != nil {
+		return errors.New(fmt.Sprintf("decoding YFull JSON, %v", err))
+	}
+	db.addYFullBranch(&root)
+	return nil
+}
+
+func (db *DB) addYFullBranch(b *yfullBranch) {
+	for _, names := range strings.Split(b.SNPs, ",") {
+		for _, name := range strings.Split(names, "/") {
+			if name = strings.TrimSpace(name); name != "" {
+				db.Add(DBRecord{Name: name, Haplogroup: b.ID})
+			}
+		}
+	}
+	for i := range b.Children {
+		db.addYFullBranch(&b.Children[i])
+	}
+}
+
+// Merge adds the records of other to the data base.
+// Records that are already in the data base have a higher priority.
+// Their empty fields are filled from other and the provenance of
+// these fields is recorded in FieldSources.
+// Records are only compared to records that were in the data base
+// before the merge, so that duplicates within other, for example
+// SNPs in palindromic regions, are all added.
+// Records whose name is in the data base only with different positions
+// and records whose position is in the data base under a different
+// name are reported as conflicts. The latter are added by name only,
+// so that they do not replace the existing entry for their SNP.
+// Records without position, like those of YFull sources, only fill
+// the fields of the records with the same name and are not added.
+func (db *DB) Merge(other *DB) (conflicts []DBConflict) {
+	merged := make(map[*DBRecord]bool)
+	for _, rec := range other.records {
+		if rec.Key.Pos == 0 {
+			for _, e := range db.previousByName(rec.Name, merged) {
+				e.fillFrom(rec)
+			}
+			continue
+		}
+		if rec.Name != "" {
+			if existing := db.previousByName(rec.Name, merged); len(existing) > 0 {
+				var same *DBRecord
+				for _, e := range existing {
+					if e.Key.Pos == rec.Key.Pos {
+						same = e
+						break
+					}
+				}
+				if same != nil {
+					same.fillFrom(rec)
+				} else {
+					conflicts = append(conflicts, DBConflict{Kind: NamePositionConflict, Existing: existing[0], Record: rec})
+				}
+				continue
+			}
+		}
+		entry := *rec
+		byKey := true
+		for _, e := range db.snpPositions[rec.Key.Pos] {
+			if !merged[e] && e.Name != rec.Name {
+				conflicts = append(conflicts, DBConflict{Kind: PositionNameConflict, Existing: e, Record: rec})
+				byKey = false
+				break
+			}
+		}
+		db.add(&entry, byKey)
+		merged[&entry] = true
+	}
+	return conflicts
+}
+
+// previousByName returns all records with the given name
+// that are not contained in merged.
+func (db *DB) previousByName(name string, merged map[*DBRecord]bool) []*DBRecord {
+	var result []*DBRecord
+	if r, exists := db.snpNames[name]; exists && !merged[r] {
+		result = append(result, r)
+	}
+	for _, r := range db.duplicateNames[name] {
+		if !merged[r] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// fillFrom fills the empty fields of r with the values of other
+// and records the source of these fields.
+func (r *DBRecord) fillFrom(other *DBRecord) {
+	if r.Haplogroup == "" && other.Haplogroup != "" {
+		r.Haplogroup = other.Haplogroup
+		r.setFieldSource("Haplogroup", other.Source)
+	}
+	if r.YCCHaplogroup == "" && other.YCCHaplogroup != "" {
+		r.YCCHaplogroup = other.YCCHaplogroup
+		r.setFieldSource("YCCHaplogroup", other.Source)
+	}
+	if r.Mutation == "" && other.Mutation != "" {
+		r.Mutation = other.Mutation
+		r.setFieldSource("Mutation", other.Source)
+	}
+	if r.Reference == "" && other.Reference != "" {
+		r.Reference = other.Reference
+		r.setFieldSource("Reference", other.Source)
+	}
+	if r.Comment == "" && other.Comment != "" {
+		r.Comment = other.Comment
+		r.setFieldSource("Comment", other.Source)
+	}
+}
+
+// setFieldSource records source as provenance of a field.
+func (r *DBRecord) setFieldSource(field, source string) {
+	if source == r.Source {
+		return
+	}
+	if r.FieldSources == nil {
+		r.FieldSources = make(map[string]string)
+	}
+	r.FieldSources[field] = source
+}
@@ -0,0 +1,152 @@
+package snp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	record := func(pos int, ref, alt, name, source string) DBRecord {
+		return DBRecord{Key: SNP{Pos: pos, Ref: ref, Alt: alt}, Name: name, Source: source}
+	}
+	tests := []struct {
+		name      string
+		sources   [][]DBRecord
+		records   int
+		conflicts []string
+	}{
+		{
+			name: "palindromic SNP in one source",
+			sources: [][]DBRecord{{
+				record(7000000, "A", "T", "Z1", "isogg"),
+				record(9000000, "G", "C", "Z1", "isogg"),
+			}},
+			records: 2,
+		},
+		{
+			name: "palindromic SNP in two sources",
+			sources: [][]DBRecord{
+				{record(7000000, "A", "T", "Z1", "isogg"), record(9000000, "G", "C", "Z1", "isogg")},
+				{record(7000000, "A", "T", "Z1", "private"), record(9000000, "G", "C", "Z1", "private")},
+			},
+			records: 2,
+		},
+		{
+			name: "same name, different position",
+			sources: [][]DBRecord{
+				{record(100, "A", "G", "L21", "isogg")},
+				{record(999, "A", "G", "L21", "private")},
+			},
+			records:   1,
+			conflicts: []string{NamePositionConflict},
+		},
+		{
+			name: "same position, different name",
+			sources: [][]DBRecord{
+				{record(100, "A", "G", "M269", "isogg")},
+				{record(100, "A", "G", "FGC1", "private")},
+			},
+			records:   2,
+			conflicts: []string{PositionNameConflict},
+		},
+		{
+			name: "same position, different alleles",
+			sources: [][]DBRecord{
+				{record(100, "A", "G", "M269", "isogg")},
+				{record(100, "A", "C", "FGC2", "private")},
+			},
+			records:   2,
+			conflicts: []string{PositionNameConflict},
+		},
+		{
+			name: "aliases in one source",
+			sources: [][]DBRecord{{
+				record(100, "A", "G", "M269", "isogg"),
+				record(100, "A", "G", "S3", "isogg"),
+			}},
+			records: 2,
+		},
+	}
+	for _, test := range tests {
+		db := NewDB()
+		var conflicts []DBConflict
+		for _, source := range test.sources {
+			other := NewDB()
+			for _, r := range source {
+				other.Add(r)
+			}
+			conflicts = append(conflicts, db.Merge(other)...)
+		}
+		if len(db.Records()) != test.records {
+			t.Errorf("%s: got %d records, want %d", test.name, len(db.Records()), test.records)
+		}
+		if len(conflicts) != len(test.conflicts) {
+			t.Errorf("%s: got %d conflicts, want %d", test.name, len(conflicts), len(test.conflicts))
+			continue
+		}
+		for i, c := range conflicts {
+			if c.Kind != test.conflicts[i] {
+				t.Errorf("%s: got conflict %s, want %s", test.name, c.Kind, test.conflicts[i])
+			}
+		}
+	}
+
+	// Entries of the higher priority source are kept.
+	db := NewDB()
+	isogg, private := NewDB(), NewDB()
+	isogg.Add(record(100, "A", "G", "M269", "isogg"))
+	private.Add(record(100, "A", "G", "FGC1", "private"))
+	db.Merge(isogg)
+	db.Merge(private)
+	if r, _ := db.EntryByKey(SNP{Pos: 100, Ref: "A", Alt: "G"}); r.Name != "M269" {
+		t.Errorf("entry for SNP replaced by %s", r.Name)
+	}
+	if _, exists := db.EntryByName("FGC1"); !exists {
+		t.Error("conflicting name not added")
+	}
+}
+
+func TestMergeYFull(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "ytree.json")
+	ytree := `{"id":"R-M269","snps":"M269/S3","children":[{"id":"R-L21","snps":"L21, Y999","children":[]}]}`
+	if err := os.WriteFile(filename, []byte(ytree), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yfull := NewDB()
+	if err := yfull.ReadYFull(filename); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(yfull.Records()); got != 4 {
+		t.Fatalf("got %d YFull records, want 4", got)
+	}
+	for _, r := range yfull.Records() {
+		r.Source = "yfull"
+	}
+
+	db := NewDB()
+	isogg := NewDB()
+	isogg.Add(DBRecord{Key: SNP{Pos: 100, Ref: "A", Alt: "G"}, Name: "M269", Haplogroup: "R1b1a1b", Source: "isogg"})
+	isogg.Add(DBRecord{Key: SNP{Pos: 200, Ref: "C", Alt: "T"}, Name: "L21", Source: "isogg"})
+	db.Merge(isogg)
+	if conflicts := db.Merge(yfull); len(conflicts) != 0 {
+		t.Errorf("got conflicts %v", conflicts)
+	}
+	if got := len(db.Records()); got != 2 {
+		t.Errorf("got %d records, want 2", got)
+	}
+	tests := []struct {
+		name, haplogroup, source string
+	}{
+		{"M269", "R1b1a1b", ""},
+		{"L21", "R-L21", "yfull"},
+	}
+	for _, test := range tests {
+		r, _ := db.EntryByName(test.name)
+		if r.Haplogroup != test.haplogroup || r.FieldSources["Haplogroup"] != test.source {
+			t.Errorf("%s: got haplogroup %s from %q, want %s from %q", test.name,
+				r.Haplogroup, r.FieldSources["Haplogroup"], test.haplogroup, test.source)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package snp
+
+import (
+	"math"
+)
+
+// KitDistance is the result of the comparison of the SNPs of two kits A and B.
+type KitDistance struct {
+	// Shared is the number of SNPs of both kits.
+	Shared int
+	// PrivateA and PrivateB are the numbers of SNPs that
+	// are only contained in kit A or kit B.
+	PrivateA int
+	PrivateB int
+	// Jaccard is the Jaccard similarity, the number of shared SNPs
+	// divided by the number of SNPs of both kits together.
+	Jaccard float64
+	// Callable is the number of base pairs that are callable in both
+	// kits. It is 0 if the callable regions are unknown.
+	Callable int
+	// Distance is the number of private SNPs in regions that are
+	// callable in both kits per million callable base pairs.
+	// If the callable regions are unknown, Distance is the number
+	// of all private SNPs. If the kits have no callable regions in
+	// common, Distance is NaN.
+	Distance float64
+}
+
+// CompareKits compares the SNPs a and b of two kits.
+// callableA and callableB are the callable regions of the kits.
+// They may be nil if they are unknown.
+func CompareKits(a, b SNPs, callableA, callableB BEDRegions) KitDistance {
+	var d KitDistance
+	shared := a.Copy()
+	shared.Intersection(b)
+	privateA := a.Copy()
+	privateA.Difference(b)
+	privateB := b.Copy()
+	privateB.Difference(a)
+
+	d.Shared = len(shared)
+	d.PrivateA = len(privateA)
+	d.PrivateB = len(privateB)
+	if all := d.Shared + d.PrivateA + d.PrivateB; all > 0 {
+		d.Jaccard = float64(d.Shared) / float64(all)
+	}
+
+	if callableA == nil || callableB == nil {
+		d.Distance = float64(d.PrivateA + d.PrivateB)
+		return d
+	}
+	common := callableA.Intersection(callableB)
+	d.Callable = common.Length()
+	if d.Callable == 0 {
+		d.Distance = math.NaN()
+		return d
+	}
+	private := 0
+	for _, s := range privateA {
+		if common.Includes(s.Pos) {
+			private++
+		}
+	}
+	for _, s := range privateB {
+		if common.Includes(s.Pos) {
+			private++
+		}
+	}
+	d.Distance = float64(private) / (float64(d.Callable) / 1e6)
+	return d
+}
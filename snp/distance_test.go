@@ -0,0 +1,75 @@
+package snp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareKits(t *testing.T) {
+	a := positions(100, 200, 300, 2000000)
+	b := positions(200, 400)
+	tests := []struct {
+		name                       string
+		callableA, callableB       BEDRegions
+		shared, privateA, privateB int
+		jaccard                    float64
+		callable                   int
+		distance                   float64
+	}{
+		{
+			name:   "without BED files",
+			shared: 1, privateA: 3, privateB: 1, jaccard: 0.2,
+			distance: 4,
+		},
+		{
+			name:      "one BED file",
+			callableA: BEDRegions{{Start: 0, End: 1000}},
+			shared:    1, privateA: 3, privateB: 1, jaccard: 0.2,
+			distance: 4,
+		},
+		{
+			name:      "common callable regions",
+			callableA: BEDRegions{{Start: 0, End: 500000}},
+			callableB: BEDRegions{{Start: 250, End: 3000000}},
+			shared:    1, privateA: 3, privateB: 1, jaccard: 0.2,
+			callable: 499750,
+			// Only SNPs 300 and 400 are in the common region.
+			distance: 2 / 0.49975,
+		},
+		{
+			name:      "no common callable regions",
+			callableA: BEDRegions{{Start: 0, End: 1000}},
+			callableB: BEDRegions{{Start: 1000, End: 2000}},
+			shared:    1, privateA: 3, privateB: 1, jaccard: 0.2,
+			distance: math.NaN(),
+		},
+		{
+			name:      "empty BED file",
+			callableA: BEDRegions{},
+			callableB: BEDRegions{{Start: 0, End: 2000}},
+			shared:    1, privateA: 3, privateB: 1, jaccard: 0.2,
+			distance: math.NaN(),
+		},
+	}
+	for _, test := range tests {
+		d := CompareKits(a, b, test.callableA, test.callableB)
+		if d.Shared != test.shared || d.PrivateA != test.privateA || d.PrivateB != test.privateB {
+			t.Errorf("%s: got %d shared, %d/%d private, want %d, %d/%d", test.name,
+				d.Shared, d.PrivateA, d.PrivateB, test.shared, test.privateA, test.privateB)
+		}
+		if math.Abs(d.Jaccard-test.jaccard) > 1e-9 {
+			t.Errorf("%s: got Jaccard %g, want %g", test.name, d.Jaccard, test.jaccard)
+		}
+		if d.Callable != test.callable {
+			t.Errorf("%s: got callable %d, want %d", test.name, d.Callable, test.callable)
+		}
+		if math.IsNaN(test.distance) != math.IsNaN(d.Distance) ||
+			!math.IsNaN(d.Distance) && math.Abs(d.Distance-test.distance) > 1e-9 {
+			t.Errorf("%s: got distance %g, want %g", test.name, d.Distance, test.distance)
+		}
+	}
+	// The kits are not modified.
+	if len(a) != 4 || len(b) != 2 {
+		t.Errorf("kits modified: %v, %v", a, b)
+	}
+}
@@ -0,0 +1,148 @@
+package snp
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// Matrix is a presence/absence matrix of SNPs across kits.
+// Each row is an SNP and each column a kit. A cell tells whether a
+// kit is derived or ancestral for an SNP or if there is no call.
+// The cells are stored in two bit sets.
+type Matrix struct {
+	SNPs []SNP
+	Kits []string
+	// derived and called are bit sets in row major order.
+	derived []uint64
+	called  []uint64
+}
+
+// NewMatrix creates a matrix from the SNP sets of several kits.
+// The rows contain all SNPs of all kits sorted by position.
+// callable contains the callable regions for each kit. A kit is
+// ancestral for an SNP if it does not contain the SNP but its position
+// is callable. If callable is nil or contains nil for a kit, all
+// positions are considered callable for that kit.
+func NewMatrix(kits []string, sets []SNPs, callable []BEDRegions) *Matrix {
+	var all SNPs
+	for _, set := range sets {
+		all.Union(set)
+	}
+	m := &Matrix{SNPs: all, Kits: kits}
+	size := (len(m.SNPs)*len(kits) + 63) / 64
+	m.derived = make([]uint64, size)
+	m.called = make([]uint64, size)
+	for col, set := range sets {
+		var regions BEDRegions
+		if callable != nil && callable[col] != nil {
+			regions = append(regions, callable[col]...)
+			regions.Normalize()
+		}
+		r, k := 0, 0
+		for row, s := range m.SNPs {
+			i := row*len(kits) + col
+			// Both m.SNPs and set are sorted and set is a subset of m.SNPs.
+			if k < len(set) && set[k] == s {
+				setBit(m.derived, i)
+				setBit(m.called, i)
+				k++
+				continue
+			}
+			if callable == nil || callable[col] == nil {
+				setBit(m.called, i)
+				continue
+			}
+			// SNPs and regions are sorted, so that
+			// the regions can be scanned only once.
+			for r < len(regions) && regions[r].End <= s.Pos {
+				r++
+			}
+			if r < len(regions) && regions[r].Includes(s.Pos) {
+				setBit(m.called, i)
+			}
+		}
+	}
+	return m
+}
+
+// Get returns the state of the kit in column col for the SNP in row row.
+func (m *Matrix) Get(row, col int) Polarity {
+	i := row*len(m.Kits) + col
+	switch {
+	case getBit(m.derived, i):
+		return Derived
+	case getBit(m.called, i):
+		return Ancestral
+	}
+	return Unknown
+}
+
+// Informative returns a matrix that contains only informative SNPs.
+// An SNP is informative if at least two kits are derived and at
+// least one kit is ancestral.
+func (m *Matrix) Informative() *Matrix {
+	var rows []int
+	for row := range m.SNPs {
+		derived, ancestral := 0, 0
+		for col := range m.Kits {
+			switch m.Get(row, col) {
+			case Derived:
+				derived++
+			case Ancestral:
+				ancestral++
+			}
+		}
+		if derived >= 2 && ancestral >= 1 {
+			rows = append(rows, row)
+		}
+	}
+	result := &Matrix{Kits: m.Kits}
+	size := (len(rows)*len(m.Kits) + 63) / 64
+	result.derived = make([]uint64, size)
+	result.called = make([]uint64, size)
+	for newRow, row := range rows {
+		result.SNPs = append(result.SNPs, m.SNPs[row])
+		for col := range m.Kits {
+			i := row*len(m.Kits) + col
+			j := newRow*len(m.Kits) + col
+			if getBit(m.derived, i) {
+				setBit(result.derived, j)
+			}
+			if getBit(m.called, i) {
+				setBit(result.called, j)
+			}
+		}
+	}
+	return result
+}
+
+// Write writes the matrix as a table with the columns Pos, Ref, Alt
+// followed by one column per kit. The first line contains the column
+// names. Derived SNPs are marked with +, ancestral with - and no-calls
+// with ?. sep is the column separator, for example comma or tab.
+func (m *Matrix) Write(w io.Writer, sep string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("Pos" + sep + "Ref" + sep + "Alt")
+	for _, kit := range m.Kits {
+		bw.WriteString(sep + kit)
+	}
+	bw.WriteString("\r\n")
+	symbols := map[Polarity]string{Derived: "+", Ancestral: "-", Unknown: "?"}
+	for row, s := range m.SNPs {
+		bw.WriteString(strconv.Itoa(s.Pos) + sep + s.Ref + sep + s.Alt)
+		for col := range m.Kits {
+			bw.WriteString(sep + symbols[m.Get(row, col)])
+		}
+		bw.WriteString("\r\n")
+	}
+	return bw.Flush()
+}
+
+func setBit(bits []uint64, i int) {
+	bits[i/64] |= 1 << uint(i%64)
+}
+
+func getBit(bits []uint64, i int) bool {
+	return bits[i/64]&(1<<uint(i%64)) != 0
+}
@@ -0,0 +1,83 @@
+package snp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMatrix(t *testing.T) {
+	kits := []string{"a", "b", "c", "d"}
+	sets := []SNPs{positions(100, 200), positions(200, 300), positions(300), nil}
+	callable := []BEDRegions{
+		nil,
+		{{Start: 250, End: 400}, {Start: 0, End: 150}},
+		{{Start: 150, End: 250}},
+		{{Start: 0, End: 1000}},
+	}
+	m := NewMatrix(kits, sets, callable)
+	var b bytes.Buffer
+	if err := m.Write(&b, ","); err != nil {
+		t.Fatal(err)
+	}
+	want := "Pos,Ref,Alt,a,b,c,d\r\n" +
+		"100,C,T,+,-,?,-\r\n" +
+		"200,C,T,+,+,-,-\r\n" +
+		"300,C,T,-,+,+,-\r\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	// SNP 100 is derived in only one kit.
+	informative := m.Informative()
+	b.Reset()
+	if err := informative.Write(&b, "\t"); err != nil {
+		t.Fatal(err)
+	}
+	want = "Pos\tRef\tAlt\ta\tb\tc\td\r\n" +
+		"200\tC\tT\t+\t+\t-\t-\r\n" +
+		"300\tC\tT\t-\t+\t+\t-\r\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestMatrixBitSets(t *testing.T) {
+	// 30 SNPs and 5 kits need several words per bit set.
+	// Kit k is derived for SNPs whose index is a multiple of k+1
+	// and has no call for the others in odd rows.
+	const rows, cols = 30, 5
+	var kits []string
+	var sets []SNPs
+	var callable []BEDRegions
+	for col := 0; col < cols; col++ {
+		kits = append(kits, string(rune('a'+col)))
+		var set SNPs
+		var regions BEDRegions
+		for row := 0; row < rows; row++ {
+			pos := (row + 1) * 10
+			if row%(col+1) == 0 {
+				set = append(set, SNP{Pos: pos, Ref: "C", Alt: "T"})
+			}
+			if row%2 == 0 {
+				regions = append(regions, BEDRegion{Start: pos, End: pos + 1})
+			}
+		}
+		sets = append(sets, set)
+		callable = append(callable, regions)
+	}
+	m := NewMatrix(kits, sets, callable)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			want := Unknown
+			switch {
+			case row%(col+1) == 0:
+				want = Derived
+			case row%2 == 0:
+				want = Ancestral
+			}
+			if got := m.Get(row, col); got != want {
+				t.Errorf("row %d, column %d: got %v, want %v", row, col, got, want)
+			}
+		}
+	}
+}
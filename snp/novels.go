@@ -0,0 +1,64 @@
+package snp
+
+import (
+	"sort"
+)
+
+// Novel returns the SNPs that are not contained in the data base.
+// SNPs that match data base entries only on the opposite strand are
+// not novel. If byPosition is true, SNPs at positions of data base
+// entries are not novel, even if their alleles are different.
+func (db *DB) Novel(snps SNPs, byPosition bool) SNPs {
+	result := snps.Copy()
+	result.Filter(func(s SNP) bool {
+		if byPosition {
+			_, exists := db.EntryByPosition(s.Pos)
+			return !exists
+		}
+		_, exists := db.MatchByKey(s)
+		return !exists
+	})
+	return result
+}
+
+// SNPGroup is a group of SNPs that are derived in the same kits.
+type SNPGroup struct {
+	// Kits are the columns of the kits in the matrix.
+	Kits []int
+	// Rows are the rows of the SNPs in the matrix.
+	Rows []int
+}
+
+// Groups groups the SNPs of the matrix by the kits that are derived
+// for them. Only SNPs that are derived in at least min kits are
+// included. The groups are sorted by decreasing number of kits and
+// the position of their first SNP.
+func (m *Matrix) Groups(min int) []SNPGroup {
+	var groups []SNPGroup
+	index := make(map[string]int)
+	for row := range m.SNPs {
+		var kits []int
+		key := make([]byte, len(m.Kits))
+		for col := range m.Kits {
+			key[col] = '0'
+			if m.Get(row, col) == Derived {
+				kits = append(kits, col)
+				key[col] = '1'
+			}
+		}
+		if len(kits) < min || len(kits) == 0 {
+			continue
+		}
+		i, exists := index[string(key)]
+		if !exists {
+			i = len(groups)
+			index[string(key)] = i
+			groups = append(groups, SNPGroup{Kits: kits})
+		}
+		groups[i].Rows = append(groups[i].Rows, row)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Kits) > len(groups[j].Kits)
+	})
+	return groups
+}
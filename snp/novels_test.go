@@ -0,0 +1,62 @@
+package snp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNovel(t *testing.T) {
+	db := NewDB()
+	db.Add(DBRecord{Key: SNP{Pos: 100, Ref: "C", Alt: "T"}, Name: "M269"})
+	db.Add(DBRecord{Key: SNP{Pos: 200, Ref: "A", Alt: "G"}, Name: "L21"})
+	snps := NewSNPs([]SNP{
+		{Pos: 100, Ref: "C", Alt: "T"},
+		{Pos: 100, Ref: "C", Alt: "A"},
+		{Pos: 200, Ref: "T", Alt: "C"},
+		{Pos: 300, Ref: "G", Alt: "A"},
+	})
+	tests := []struct {
+		byPosition bool
+		want       SNPs
+	}{
+		{false, SNPs{{Pos: 100, Ref: "C", Alt: "A"}, {Pos: 300, Ref: "G", Alt: "A"}}},
+		{true, SNPs{{Pos: 300, Ref: "G", Alt: "A"}}},
+	}
+	for _, test := range tests {
+		if got := db.Novel(snps, test.byPosition); !equalSNPs(got, test.want) {
+			t.Errorf("byPosition=%v: got %v, want %v", test.byPosition, got, test.want)
+		}
+	}
+	if len(snps) != 4 {
+		t.Errorf("input modified: %v", snps)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	kits := []string{"a", "b", "c", "d"}
+	sets := []SNPs{positions(100, 200, 300), positions(100, 200, 300, 400), positions(100, 400), positions(500)}
+	// Kit c has no callable regions, like kits without BED files in novels.
+	callable := []BEDRegions{nil, nil, {}, nil}
+	m := NewMatrix(kits, sets, callable)
+	tests := []struct {
+		min  int
+		want string
+	}{
+		{2, "[{[0 1 2] [0]} {[0 1] [1 2]} {[1 2] [3]}]"},
+		{3, "[{[0 1 2] [0]}]"},
+		{1, "[{[0 1 2] [0]} {[0 1] [1 2]} {[1 2] [3]} {[3] [4]}]"},
+		{0, "[{[0 1 2] [0]} {[0 1] [1 2]} {[1 2] [3]} {[3] [4]}]"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprint(m.Groups(test.min)); got != test.want {
+			t.Errorf("min=%d: got %s, want %s", test.min, got, test.want)
+		}
+	}
+	// Kit c is uncovered for the SNPs it does not have.
+	if got := m.Get(1, 2); got != Unknown {
+		t.Errorf("got %v for kit without BED file, want unknown", got)
+	}
+	if got := m.Get(1, 3); got != Ancestral {
+		t.Errorf("got %v for kit with all positions callable, want ancestral", got)
+	}
+}
@@ -0,0 +1,141 @@
+package snp
+
+import (
+	"strconv"
+)
+
+// Polarity is the state of an SNP call relative to the SNP tree.
+type Polarity int
+
+const (
+	// Unknown means that the position is not in the data base or
+	// that the called allele is neither ancestral nor derived.
+	Unknown Polarity = iota
+	Ancestral
+	Derived
+)
+
+// String returns the name of the polarity.
+func (p Polarity) String() string {
+	switch p {
+	case Ancestral:
+		return "ancestral"
+	case Derived:
+		return "derived"
+	}
+	return "unknown"
+}
+
+// Polarize determines if an SNP call is ancestral or derived according
+// to the data base. s.Alt is the called allele. This is independent of
+// the reference genome, which itself is derived for many SNPs.
+// The matching data base record is returned unless the polarity is Unknown.
+func (db *DB) Polarize(s SNP) (Polarity, *DBRecord) {
+	for _, rec := range db.snpPositions[s.Pos] {
+		switch s.Alt {
+		case rec.Key.Alt:
+			return Derived, rec
+		case rec.Key.Ref:
+			return Ancestral, rec
+		}
+	}
+	return Unknown, nil
+}
+
+// PolarizeSNPs splits SNP calls into derived, ancestral and unknown SNPs.
+// Derived and ancestral SNPs are returned in data base orientation:
+// Ref is the ancestral and Alt the derived allele.
+// Calls that can not be polarized are returned unchanged in unknown
+// if they are mutations relative to the reference (Ref != Alt).
+func (db *DB) PolarizeSNPs(calls SNPs) (derived, ancestral, unknown SNPs) {
+	for _, s := range calls {
+		polarity, rec := db.Polarize(s)
+		switch polarity {
+		case Derived:
+			derived = append(derived, rec.Key)
+		case Ancestral:
+			ancestral = append(ancestral, rec.Key)
+		default:
+			if s.Ref != s.Alt {
+				unknown = append(unknown, s)
+			}
+		}
+	}
+	return NewSNPs(derived), NewSNPs(ancestral), unknown
+}
+
+// PolarizeKits orients the SNP sets of several kits like the data
+// base, so that a tree can be built from them. At positions where
+// the reference genome carries the derived allele, the kits list the
+// ancestral allele as mutation. These kits become ancestral and the
+// kits that are callable at the position without a call become
+// derived for the SNP in data base orientation. callable contains
+// the callable regions of the kits as for NewMatrix. The sets are
+// not modified.
+func (db *DB) PolarizeKits(sets []SNPs, callable []BEDRegions) []SNPs {
+	// Find the calls of the ancestral allele at positions
+	// where the reference carries the derived allele.
+	reversed := make(map[SNP]SNP)
+	var all SNPs
+	for _, set := range sets {
+		all.Union(set)
+	}
+	for _, s := range all {
+		if polarity, rec := db.Polarize(s); polarity == Ancestral && s.Ref == rec.Key.Alt {
+			reversed[s] = rec.Key
+		}
+	}
+	result := make([]SNPs, len(sets))
+	for i, set := range sets {
+		var list []SNP
+		calls := make(map[int]bool, len(set))
+		for _, s := range set {
+			calls[s.Pos] = true
+			if _, exists := reversed[s]; !exists {
+				list = append(list, s)
+			}
+		}
+		for s, key := range reversed {
+			if calls[s.Pos] {
+				continue
+			}
+			if callable == nil || callable[i] == nil || callable[i].Includes(s.Pos) {
+				list = append(list, key)
+			}
+		}
+		result[i] = NewSNPs(list)
+	}
+	return result
+}
+
+// PolarizeCSV works like PolarizeSNPs for CSV records.
+// Records of polarized calls get the alleles and, if it is missing,
+// the name of the data base record.
+// Records without a valid position are returned in unknown.
+func (db *DB) PolarizeCSV(recs CSVRecords) (derived, ancestral, unknown CSVRecords) {
+	for _, r := range recs {
+		pos, err := strconv.Atoi(r.Pos)
+		if err != nil {
+			unknown = append(unknown, r)
+			continue
+		}
+		polarity, rec := db.Polarize(SNP{Pos: pos, Ref: r.Ref, Alt: r.Alt})
+		if polarity != Unknown {
+			r.Ref, r.Alt = rec.Key.Ref, rec.Key.Alt
+			if r.Name == "" {
+				r.Name = rec.Name
+			}
+		}
+		switch polarity {
+		case Derived:
+			derived = append(derived, r)
+		case Ancestral:
+			ancestral = append(ancestral, r)
+		default:
+			if r.Ref != r.Alt {
+				unknown = append(unknown, r)
+			}
+		}
+	}
+	return derived, ancestral, unknown
+}
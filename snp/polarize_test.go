@@ -0,0 +1,134 @@
+package snp
+
+import (
+	"testing"
+)
+
+// polarizeDB returns a data base with a C->T SNP at 100 and
+// an SNP at 200 for which the reference genome is derived.
+func polarizeDB() *DB {
+	db := NewDB()
+	db.Add(DBRecord{Key: SNP{Pos: 100, Ref: "C", Alt: "T"}, Name: "M269"})
+	db.Add(DBRecord{Key: SNP{Pos: 200, Ref: "A", Alt: "G"}, Name: "L21"})
+	return db
+}
+
+func TestPolarize(t *testing.T) {
+	db := polarizeDB()
+	tests := []struct {
+		name     string
+		call     SNP
+		polarity Polarity
+		record   string
+	}{
+		{"derived", SNP{Pos: 100, Ref: "C", Alt: "T"}, Derived, "M269"},
+		{"ancestral reference", SNP{Pos: 100, Ref: "C", Alt: "C"}, Ancestral, "M269"},
+		{"derived reference", SNP{Pos: 200, Ref: "G", Alt: "G"}, Derived, "L21"},
+		{"ancestral mutation", SNP{Pos: 200, Ref: "G", Alt: "A"}, Ancestral, "L21"},
+		{"other allele", SNP{Pos: 100, Ref: "C", Alt: "A"}, Unknown, ""},
+		{"unknown position", SNP{Pos: 300, Ref: "C", Alt: "T"}, Unknown, ""},
+	}
+	for _, test := range tests {
+		polarity, rec := db.Polarize(test.call)
+		if polarity != test.polarity {
+			t.Errorf("%s: got %v, want %v", test.name, polarity, test.polarity)
+		}
+		name := ""
+		if rec != nil {
+			name = rec.Name
+		}
+		if name != test.record {
+			t.Errorf("%s: got record %q, want %q", test.name, name, test.record)
+		}
+	}
+}
+
+func TestPolarizeSNPs(t *testing.T) {
+	calls := NewSNPs([]SNP{
+		{Pos: 100, Ref: "C", Alt: "T"},
+		{Pos: 200, Ref: "G", Alt: "A"},
+		{Pos: 300, Ref: "C", Alt: "T"},
+		{Pos: 400, Ref: "C", Alt: "C"},
+	})
+	derived, ancestral, unknown := polarizeDB().PolarizeSNPs(calls)
+	tests := []struct {
+		name      string
+		got, want SNPs
+	}{
+		{"derived", derived, SNPs{{Pos: 100, Ref: "C", Alt: "T"}}},
+		{"ancestral", ancestral, SNPs{{Pos: 200, Ref: "A", Alt: "G"}}},
+		{"unknown", unknown, SNPs{{Pos: 300, Ref: "C", Alt: "T"}}},
+	}
+	for _, test := range tests {
+		if !equalSNPs(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestPolarizeCSV(t *testing.T) {
+	recs := CSVRecords{
+		{Pos: "100", Ref: "C", Alt: "T"},
+		{Pos: "200", Ref: "G", Alt: "G", Name: "S145"},
+		{Pos: "200", Ref: "G", Alt: "A"},
+		{Pos: "n/a", Name: "BY123"},
+		{Pos: "300", Ref: "C", Alt: "C"},
+	}
+	derived, ancestral, unknown := polarizeDB().PolarizeCSV(recs)
+	tests := []struct {
+		name      string
+		got, want CSVRecords
+	}{
+		{"derived", derived, CSVRecords{{Pos: "100", Ref: "C", Alt: "T", Name: "M269"}, {Pos: "200", Ref: "A", Alt: "G", Name: "S145"}}},
+		{"ancestral", ancestral, CSVRecords{{Pos: "200", Ref: "A", Alt: "G", Name: "L21"}}},
+		{"unknown", unknown, CSVRecords{{Pos: "n/a", Name: "BY123"}}},
+	}
+	for _, test := range tests {
+		if len(test.got) != len(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+			continue
+		}
+		for i := range test.got {
+			if test.got[i] != test.want[i] {
+				t.Errorf("%s: got %v, want %v", test.name, test.got[i], test.want[i])
+			}
+		}
+	}
+}
+
+func TestPolarizeKits(t *testing.T) {
+	// The reference carries the derived allele G of L21 at 200.
+	// Kits a and b are derived for M269 and L21, kit c only for
+	// M269 and kit d is not callable at 200.
+	sets := []SNPs{
+		NewSNPs([]SNP{{Pos: 100, Ref: "C", Alt: "T"}}),
+		NewSNPs([]SNP{{Pos: 100, Ref: "C", Alt: "T"}, {Pos: 300, Ref: "A", Alt: "C"}}),
+		NewSNPs([]SNP{{Pos: 100, Ref: "C", Alt: "T"}, {Pos: 200, Ref: "G", Alt: "A"}}),
+		NewSNPs([]SNP{{Pos: 100, Ref: "C", Alt: "T"}}),
+	}
+	callable := []BEDRegions{nil, {{Start: 0, End: 1000}}, nil, {{Start: 0, End: 150}}}
+	polarized := polarizeDB().PolarizeKits(sets, callable)
+	l21 := SNP{Pos: 200, Ref: "A", Alt: "G"}
+	want := []SNPs{
+		{{Pos: 100, Ref: "C", Alt: "T"}, l21},
+		{{Pos: 100, Ref: "C", Alt: "T"}, l21, {Pos: 300, Ref: "A", Alt: "C"}},
+		{{Pos: 100, Ref: "C", Alt: "T"}},
+		{{Pos: 100, Ref: "C", Alt: "T"}},
+	}
+	for i := range want {
+		if !equalSNPs(polarized[i], want[i]) {
+			t.Errorf("kit %d: got %v, want %v", i, polarized[i], want[i])
+		}
+	}
+	if len(sets[2]) != 2 {
+		t.Errorf("sets modified: %v", sets)
+	}
+
+	// Without calls of the ancestral allele nothing changes.
+	polarized = polarizeDB().PolarizeKits(sets[:2], nil)
+	for i := range polarized {
+		if !equalSNPs(polarized[i], sets[i]) {
+			t.Errorf("kit %d: got %v, want %v", i, polarized[i], sets[i])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package snp
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Resolve finds the data base entries for an SNP name as it is used in
+// publications or haplotrees. Synonyms that are joined by slashes,
+// for example L21/S145, must all denote the same SNP. Haplogroup
+// prefixes like R- in R-M269 are removed if the full name is unknown.
+// The result contains one entry per SNP. If there are several entries,
+// the name is ambiguous. If there are none, it could not be resolved.
+func (db *DB) Resolve(name string) []*DBRecord {
+	name = strings.TrimSpace(name)
+	var result []*DBRecord
+	seen := make(map[SNP]bool)
+	for _, n := range strings.Split(name, "/") {
+		entries := db.EntriesByName(n)
+		if len(entries) == 0 {
+			if i := strings.Index(n, "-"); i > 0 {
+				entries = db.EntriesByName(n[i+1:])
+			}
+		}
+		for _, e := range entries {
+			if !seen[e.Key] {
+				seen[e.Key] = true
+				result = append(result, e)
+			}
+		}
+	}
+	return result
+}
+
+// ReadNames reads a list of SNP names from a text file.
+// Names may be separated by line breaks, commas, semicolons or spaces.
+// Lines starting with # are ignored.
+func ReadNames(filename string) ([]string, error) {
+	infile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer infile.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(infile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		names = append(names, SplitNames(line)...)
+	}
+	return names, scanner.Err()
+}
+
+// SplitNames splits a list of SNP names separated by commas,
+// semicolons or white space.
+func SplitNames(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+}
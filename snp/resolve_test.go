@@ -0,0 +1,53 @@
+package snp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	db := queryDB()
+	db.Add(DBRecord{Key: SNP{Pos: 20577481, Ref: "C", Alt: "G"}, Name: "S145"})
+	db.Add(DBRecord{Key: SNP{Pos: 500, Ref: "A", Alt: "G"}, Name: "CTS-123"})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"L21", "L21@20577481"},
+		{" L21 ", "L21@20577481"},
+		{"l21", "L21@20577481"},
+		{"L21/S145", "L21@20577481"},
+		{"S145/L21", "S145@20577481"},
+		{"R-M269", "M269@22739367"},
+		{"R-L21/S145", "L21@20577481"},
+		{"CTS-123", "CTS-123@500"},
+		{"L21/M269", "L21@20577481,M269@22739367"},
+		{"Z1", "Z1@9000000,Z1@7000000"},
+		{"R-X1", ""},
+		{"X1", ""},
+		{"-M269", ""},
+	}
+	for _, test := range tests {
+		if got := recordNames(db.Resolve(test.name)); got != test.want {
+			t.Errorf("%q: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestReadNames(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "names.txt")
+	text := "# SNPs from the paper\r\nM269, L21/S145;DF13\r\n\r\n  # comment\r\nR-Z1\tFGC1\r\n"
+	if err := os.WriteFile(filename, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	names, err := ReadNames(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "M269 L21/S145 DF13 R-Z1 FGC1"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
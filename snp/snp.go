@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -20,7 +21,9 @@ type SNP struct {
 }
 
 // SNPs represents a set of SNPs.
-type SNPs map[SNP]bool
+// The SNPs are sorted by position, reference and alternative allele
+// and contain no duplicates. Set operations merge the sorted slices.
+type SNPs []SNP
 
 // String returns a string representation in CSV format
 // including CRLF.
@@ -35,30 +38,137 @@ func (s SNP) String() string {
 	return b.String()
 }
 
+// Less compares two SNPs by position, reference and alternative allele.
+func (s SNP) Less(other SNP) bool {
+	if s.Pos != other.Pos {
+		return s.Pos < other.Pos
+	}
+	if s.Ref != other.Ref {
+		return s.Ref < other.Ref
+	}
+	return s.Alt < other.Alt
+}
+
+// NewSNPs creates a set of SNPs from a list that may be unsorted
+// and may contain duplicates. The list is reused for the result.
+func NewSNPs(list []SNP) SNPs {
+	sort.Slice(list, func(i, j int) bool { return list[i].Less(list[j]) })
+	result := list[:0]
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Contains tests if the set contains the SNP s.
+func (a SNPs) Contains(s SNP) bool {
+	i := sort.Search(len(a), func(i int) bool { return !a[i].Less(s) })
+	return i < len(a) && a[i] == s
+}
+
+// Copy returns a copy of the SNPs.
+func (a SNPs) Copy() SNPs {
+	return append(SNPs(nil), a...)
+}
+
+// Filter removes all SNPs for which keep returns false.
+func (a *SNPs) Filter(keep func(s SNP) bool) {
+	result := (*a)[:0]
+	for _, s := range *a {
+		if keep(s) {
+			result = append(result, s)
+		}
+	}
+	*a = result
+}
+
 // Union calculates the set union of all SNPs in a with all SNPs in b.
 // The result is stored in a.
-func (a SNPs) Union(b SNPs) {
-	for k, _ := range b {
-		a[k] = true
+func (a *SNPs) Union(b SNPs) {
+	x := *a
+	result := make(SNPs, 0, len(x)+len(b))
+	i, j := 0, 0
+	for i < len(x) && j < len(b) {
+		switch {
+		case x[i].Less(b[j]):
+			result = append(result, x[i])
+			i++
+		case b[j].Less(x[i]):
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, x[i])
+			i++
+			j++
+		}
 	}
+	result = append(result, x[i:]...)
+	result = append(result, b[j:]...)
+	*a = result
 }
 
 // Intersection calculates the set intersection of all SNPs in a with all SNPs in b.
 // The result is stored in a.
-func (a SNPs) Intersection(b SNPs) {
-	for k, _ := range a {
-		if b[k] == false {
-			delete(a, k)
+func (a *SNPs) Intersection(b SNPs) {
+	x := *a
+	result := x[:0]
+	i, j := 0, 0
+	for i < len(x) && j < len(b) {
+		switch {
+		case x[i].Less(b[j]):
+			i++
+		case b[j].Less(x[i]):
+			j++
+		default:
+			result = append(result, x[i])
+			i++
+			j++
 		}
 	}
+	*a = result
 }
 
 // Difference calculates the set difference a\b.
 // The result is stored in a.
-func (a SNPs) Difference(b SNPs) {
-	for k, _ := range b {
-		delete(a, k)
+func (a *SNPs) Difference(b SNPs) {
+	x := *a
+	result := x[:0]
+	i, j := 0, 0
+	for i < len(x) {
+		switch {
+		case j >= len(b) || x[i].Less(b[j]):
+			result = append(result, x[i])
+			i++
+		case b[j].Less(x[i]):
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	*a = result
+}
+
+// Occurrences determines which of the given sets contain each SNP.
+// The result contains the union of all sets and for each SNP the
+// indices of the sets that contain it.
+func Occurrences(sets []SNPs) (union SNPs, indices [][]int) {
+	for _, set := range sets {
+		union.Union(set)
+	}
+	indices = make([][]int, len(union))
+	for i, set := range sets {
+		k := 0
+		for _, s := range set {
+			for union[k] != s {
+				k++
+			}
+			indices[k] = append(indices[k], i)
+		}
 	}
+	return union, indices
 }
 
 // WriteCSV writes SNPs in a simplified format:
@@ -72,7 +182,7 @@ func (s SNPs) WriteCSV(filename string) error {
 	defer outfile.Close()
 
 	writer := bufio.NewWriter(outfile)
-	for snp, _ := range s {
+	for _, snp := range s {
 		writer.WriteString(snp.String())
 	}
 	err = writer.Flush()
@@ -96,16 +206,16 @@ func ReadCSV(filename string) (SNPs, error) {
 		return nil, err
 	}
 
-	result := make(map[SNP]bool)
+	result := make([]SNP, 0, len(records))
 	for _, fields := range records {
 		snpPos, err := strconv.Atoi(fields[0])
 		if err != nil {
-			return result, errors.New(fmt.Sprintf(" parsing SNP position %v\n", err))
+			return NewSNPs(result), errors.New(fmt.Sprintf(" parsing SNP position %v\n", err))
 		}
 		snp := SNP{Pos: snpPos, Ref: fields[1], Alt: fields[2]}
-		result[snp] = true
+		result = append(result, snp)
 	}
-	return result, nil
+	return NewSNPs(result), nil
 }
 
 // ReadVCF reads SNPs from a VCF (Variant Call Format) file.
@@ -134,14 +244,14 @@ func ReadVCF(filename string, quality float64, mutationsOnly bool, reads, ratio
 		return nil, err
 	}
 
-	result := make(map[SNP]bool)
+	result := make([]SNP, 0, len(records))
 	for _, record := range records {
 		snp, exists := fieldsToSNP(record, quality, mutationsOnly, reads, ratio)
 		if exists {
-			result[snp] = true
+			result = append(result, snp)
 		}
 	}
-	return result, nil
+	return NewSNPs(result), nil
 }
 
 // fieldsToSNP tries to convert the entries of a VCF file line
@@ -252,14 +362,14 @@ func ReadYFull(filename string, quality string) (SNPs, error) {
 		return nil, err
 	}
 
-	result := make(map[SNP]bool)
+	result := make([]SNP, 0, len(records))
 	for _, record := range records {
 		snp, exists := yfullFieldsToSNP(record, quality)
 		if exists {
-			result[snp] = true
+			result = append(result, snp)
 		}
 	}
-	return result, nil
+	return NewSNPs(result), nil
 }
 
 // yfullFieldsToSNP tries to convert the entries of a YFull line
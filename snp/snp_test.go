@@ -0,0 +1,171 @@
+package snp
+
+import (
+	"strings"
+	"testing"
+)
+
+// positions returns SNPs at the given positions with fixed alleles.
+func positions(pos ...int) SNPs {
+	var list []SNP
+	for _, p := range pos {
+		list = append(list, SNP{Pos: p, Ref: "C", Alt: "T"})
+	}
+	return NewSNPs(list)
+}
+
+func TestOccurrences(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []SNPs
+		union   SNPs
+		indices [][]int
+	}{
+		{"no sets", nil, nil, [][]int{}},
+		{"empty set", []SNPs{nil, positions(1)}, positions(1), [][]int{{1}}},
+		{
+			name:    "overlapping sets",
+			sets:    []SNPs{positions(1, 3), positions(2, 3), positions(3, 4)},
+			union:   positions(1, 2, 3, 4),
+			indices: [][]int{{0}, {1}, {0, 1, 2}, {2}},
+		},
+		{
+			name:    "different alleles",
+			sets:    []SNPs{{{Pos: 1, Ref: "C", Alt: "T"}}, {{Pos: 1, Ref: "C", Alt: "G"}}},
+			union:   SNPs{{Pos: 1, Ref: "C", Alt: "G"}, {Pos: 1, Ref: "C", Alt: "T"}},
+			indices: [][]int{{1}, {0}},
+		},
+	}
+	for _, test := range tests {
+		union, indices := Occurrences(test.sets)
+		if !equalSNPs(union, test.union) {
+			t.Errorf("%s: got union %v, want %v", test.name, union, test.union)
+			continue
+		}
+		if len(indices) != len(test.indices) {
+			t.Errorf("%s: got indices %v, want %v", test.name, indices, test.indices)
+			continue
+		}
+		for i := range indices {
+			if !equalInts(indices[i], test.indices[i]) {
+				t.Errorf("%s: got indices %v, want %v", test.name, indices, test.indices)
+				break
+			}
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNewSNPs(t *testing.T) {
+	list := []SNP{
+		{Pos: 300, Ref: "G", Alt: "A"},
+		{Pos: 100, Ref: "C", Alt: "T"},
+		{Pos: 100, Ref: "A", Alt: "T"},
+		{Pos: 300, Ref: "G", Alt: "A"},
+		{Pos: 100, Ref: "C", Alt: "G"},
+	}
+	want := SNPs{
+		{Pos: 100, Ref: "A", Alt: "T"},
+		{Pos: 100, Ref: "C", Alt: "G"},
+		{Pos: 100, Ref: "C", Alt: "T"},
+		{Pos: 300, Ref: "G", Alt: "A"},
+	}
+	s := NewSNPs(list)
+	if !equalSNPs(s, want) {
+		t.Errorf("got %v, want %v", s, want)
+	}
+	for _, x := range want {
+		if !s.Contains(x) {
+			t.Errorf("%v not contained", x)
+		}
+	}
+	for _, x := range []SNP{{Pos: 100, Ref: "C", Alt: "A"}, {Pos: 50}, {Pos: 400}} {
+		if s.Contains(x) {
+			t.Errorf("%v contained", x)
+		}
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		name                            string
+		a, b                            SNPs
+		union, intersection, difference SNPs
+	}{
+		{"empty sets", nil, nil, nil, nil, nil},
+		{"empty b", positions(1, 2), nil, positions(1, 2), nil, positions(1, 2)},
+		{"empty a", nil, positions(1, 2), positions(1, 2), nil, nil},
+		{"equal sets", positions(1, 2), positions(1, 2), positions(1, 2), positions(1, 2), nil},
+		{"disjoint sets", positions(1, 3), positions(2, 4), positions(1, 2, 3, 4), nil, positions(1, 3)},
+		{"overlapping sets", positions(1, 2, 3, 5), positions(2, 3, 4), positions(1, 2, 3, 4, 5), positions(2, 3), positions(1, 5)},
+		{"subset", positions(2), positions(1, 2, 3), positions(1, 2, 3), positions(2), nil},
+		{
+			name:         "same position, different alleles",
+			a:            SNPs{{Pos: 1, Ref: "C", Alt: "T"}},
+			b:            SNPs{{Pos: 1, Ref: "C", Alt: "G"}},
+			union:        SNPs{{Pos: 1, Ref: "C", Alt: "G"}, {Pos: 1, Ref: "C", Alt: "T"}},
+			difference:   SNPs{{Pos: 1, Ref: "C", Alt: "T"}},
+			intersection: nil,
+		},
+	}
+	for _, test := range tests {
+		b := test.b.Copy()
+		union := test.a.Copy()
+		union.Union(test.b)
+		intersection := test.a.Copy()
+		intersection.Intersection(test.b)
+		difference := test.a.Copy()
+		difference.Difference(test.b)
+		if !equalSNPs(union, test.union) {
+			t.Errorf("%s: got union %v, want %v", test.name, union, test.union)
+		}
+		if !equalSNPs(intersection, test.intersection) {
+			t.Errorf("%s: got intersection %v, want %v", test.name, intersection, test.intersection)
+		}
+		if !equalSNPs(difference, test.difference) {
+			t.Errorf("%s: got difference %v, want %v", test.name, difference, test.difference)
+		}
+		if !equalSNPs(test.b, b) {
+			t.Errorf("%s: b modified to %v", test.name, test.b)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	s := positions(1, 2, 3, 4)
+	s.Filter(func(x SNP) bool { return x.Pos%2 == 0 })
+	if want := positions(2, 4); !equalSNPs(s, want) {
+		t.Errorf("got %v, want %v", s, want)
+	}
+}
+
+func TestSortCSVRecords(t *testing.T) {
+	recs := CSVRecords{
+		{Pos: "n/a", Name: "Y2"},
+		{Pos: "1000", Ref: "C", Alt: "T", Name: "B"},
+		{Pos: "200", Ref: "G", Alt: "A"},
+		{Pos: "n/a", Name: "Y1"},
+		{Pos: "1000", Ref: "C", Alt: "T", Name: "A"},
+		{Pos: "1000", Ref: "A", Alt: "T"},
+	}
+	recs.Sort()
+	var got []string
+	for _, r := range recs {
+		got = append(got, r.Pos+r.Ref+r.Alt+r.Name)
+	}
+	want := "200GA 1000AT 1000CTA 1000CTB n/aY1 n/aY2"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
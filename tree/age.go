@@ -0,0 +1,258 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// DefaultRate is the default mutation rate in SNPs per base pair and year.
+const DefaultRate = 8.2e-10
+
+// Age is the estimated time to the most recent common ancestor
+// of the kits below a branch.
+type Age struct {
+	// Years is the estimated age in years before present.
+	Years float64
+	// Lower and Upper are the bounds of the confidence interval.
+	Lower float64
+	Upper float64
+	// Mutations is the number of SNPs on all lineages from the branch
+	// to the kits and Lineages is the number of kits.
+	Mutations int
+	Lineages  int
+	// Callable is the number of base pairs used for the estimate.
+	Callable int
+}
+
+// Dating contains the parameters for age estimation.
+type Dating struct {
+	// Rate is the mutation rate in SNPs per base pair and year.
+	Rate float64
+	// Confidence is the level of the confidence interval, for example 0.95.
+	Confidence float64
+	// Gamma selects the quantiles of the gamma distribution of the age
+	// for a flat prior as confidence interval. Otherwise the exact
+	// Poisson confidence interval is used.
+	Gamma bool
+	// Length is the callable length for branches whose callable regions
+	// are unknown. If it is 0, these branches are not dated.
+	Length int
+}
+
+// Calibration is a known age of the most recent common ancestor
+// of some kits, for example from a genealogy.
+type Calibration struct {
+	Kits  []string
+	Years float64
+}
+
+// EstimateAges estimates the ages of all inner branches.
+//
+// The age is the mean number of SNPs on the lineages from the branch
+// to its kits divided by the mutation rate and the callable length.
+// Only SNPs within the callable regions of the branch are counted,
+// which are the regions callable in all kits below the branch. The
+// number of SNPs is assumed to follow a Poisson distribution.
+func (t *Tree) EstimateAges(d Dating) {
+	t.Root.Walk(func(node *Node, depth int) {
+		node.Age = nil
+		if node.IsTip() {
+			return
+		}
+		mutations, lineages, length := node.lineageMutations(d.Length)
+		if lineages == 0 || length == 0 {
+			return
+		}
+		node.Age = d.age(mutations, lineages, length)
+	})
+}
+
+// age estimates an age from the number of mutations
+// on a number of lineages in a callable region.
+func (d *Dating) age(mutations, lineages, length int) *Age {
+	a := &Age{Mutations: mutations, Lineages: lineages, Callable: length}
+	scale := float64(lineages) * float64(length) * d.Rate
+	alpha := 1 - d.Confidence
+	m := float64(mutations)
+	a.Years = m / scale
+	if d.Gamma {
+		a.Lower = gammaQuantile(m+1, alpha/2) / scale
+	} else {
+		a.Lower = gammaQuantile(m, alpha/2) / scale
+	}
+	a.Upper = gammaQuantile(m+1, 1-alpha/2) / scale
+	return a
+}
+
+// lineageMutations counts the SNPs on all lineages from n to the kits
+// below it. If the callable regions of n are known, only SNPs within
+// them are counted. Otherwise all SNPs are counted and the callable
+// length is defaultLength.
+func (n *Node) lineageMutations(defaultLength int) (mutations, lineages, length int) {
+	length = n.Callable
+	if n.regions == nil {
+		length = defaultLength
+	}
+	var count func(node *Node, m int)
+	count = func(node *Node, m int) {
+		for _, c := range node.Children {
+			cm := m + c.branchMutations(n.regions)
+			if c.Kit != "" {
+				mutations += cm
+				lineages++
+			}
+			count(c, cm)
+		}
+	}
+	count(n, 0)
+	return mutations, lineages, length
+}
+
+// branchMutations returns the number of SNPs of the branch within
+// regions. If regions is nil, the number of SNPs or the branch length
+// is returned.
+func (n *Node) branchMutations(regions snp.BEDRegions) int {
+	if regions == nil {
+		if count := n.SNPCount(); count > 0 {
+			return count
+		}
+		return int(n.Length + 0.5)
+	}
+	count := 0
+	r := 0
+	for _, s := range n.SNPs {
+		for r < len(regions) && regions[r].End <= s.Pos {
+			r++
+		}
+		if r < len(regions) && regions[r].Includes(s.Pos) {
+			count++
+		}
+	}
+	return count
+}
+
+// MRCA returns the most recent common ancestor of kits.
+func (t *Tree) MRCA(kits []string) (*Node, error) {
+	tips := make(map[string]*Node)
+	t.Root.Walk(func(node *Node, depth int) {
+		if node.Kit != "" {
+			tips[node.Kit] = node
+		}
+	})
+	var mrca []*Node
+	for i, kit := range kits {
+		tip, exists := tips[kit]
+		if !exists {
+			return nil, errors.New(fmt.Sprintf("unknown kit %s", kit))
+		}
+		path := tip.Path()
+		if i == 0 {
+			mrca = path
+			continue
+		}
+		j := 0
+		for j < len(mrca) && j < len(path) && mrca[j] == path[j] {
+			j++
+		}
+		mrca = mrca[:j]
+	}
+	if len(mrca) == 0 {
+		return nil, errors.New("no kits")
+	}
+	return mrca[len(mrca)-1], nil
+}
+
+// Calibrate estimates the mutation rate from calibration points.
+// The rate is the number of SNPs on all lineages from the common
+// ancestors of the calibration points to their kits divided by the
+// sum of lineages times years times callable length. d.Length is
+// used for branches whose callable regions are unknown.
+func (t *Tree) Calibrate(points []Calibration, d Dating) (float64, error) {
+	mutations, exposure := 0, 0.0
+	for _, p := range points {
+		node, err := t.MRCA(p.Kits)
+		if err != nil {
+			return 0, err
+		}
+		m, lineages, length := node.lineageMutations(d.Length)
+		if length == 0 {
+			return 0, errors.New(fmt.Sprintf("callable length of %s is unknown", node.Label))
+		}
+		mutations += m
+		exposure += float64(lineages) * float64(length) * p.Years
+	}
+	if mutations == 0 || exposure == 0 {
+		return 0, errors.New("calibration points contain no SNPs")
+	}
+	return float64(mutations) / exposure, nil
+}
+
+// gammaP returns the regularized lower incomplete gamma function P(a, x).
+func gammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+	if x < a+1 {
+		// Series expansion.
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if term < sum*1e-15 {
+				break
+			}
+		}
+		return sum * prefix
+	}
+	// Continued fraction for the upper incomplete gamma function.
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return 1 - prefix*h
+}
+
+// gammaQuantile returns the quantile p of the gamma distribution
+// with shape a and scale 1. It is 0 for a shape of 0.
+func gammaQuantile(a, p float64) float64 {
+	if a <= 0 || p <= 0 {
+		return 0
+	}
+	lo, hi := 0.0, a+1
+	for gammaP(a, hi) < p {
+		hi *= 2
+	}
+	for i := 0; i < 200 && hi-lo > 1e-12*hi; i++ {
+		mid := (lo + hi) / 2
+		if gammaP(a, mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,59 @@
+package tree
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGammaQuantile(t *testing.T) {
+	// Exact 95% confidence intervals for the mean of a Poisson
+	// distribution with n observed events (Garwood 1936).
+	tests := []struct {
+		n            int
+		lower, upper float64
+	}{
+		{0, 0, 3.689},
+		{1, 0.0253, 5.572},
+		{2, 0.2422, 7.225},
+		{3, 0.6186, 8.767},
+		{5, 1.6235, 11.668},
+		{10, 4.7954, 18.390},
+		{20, 12.217, 30.889},
+	}
+	for _, test := range tests {
+		n := float64(test.n)
+		lower := gammaQuantile(n, 0.025)
+		upper := gammaQuantile(n+1, 0.975)
+		if math.Abs(lower-test.lower) > 1e-3 || math.Abs(upper-test.upper) > 1e-3 {
+			t.Errorf("n=%d: got %.4f-%.4f, want %.4f-%.4f", test.n, lower, upper, test.lower, test.upper)
+		}
+	}
+}
+
+func TestEstimateAges(t *testing.T) {
+	// 2 lineages with 3 and 5 SNPs below the root.
+	tree := Build(testMatrix([]kitData{
+		{name: "a", snps: []int{1, 2, 3}},
+		{name: "b", snps: []int{4, 5, 6, 7, 8}},
+	}), nil)
+	tree.EstimateAges(Dating{Rate: 1e-9, Confidence: 0.95, Length: 1000000})
+	age := tree.Root.Age
+	if age == nil {
+		t.Fatal("root has no age")
+	}
+	if age.Mutations != 8 || age.Lineages != 2 {
+		t.Errorf("got %d SNPs on %d lineages, want 8 on 2", age.Mutations, age.Lineages)
+	}
+	// 8 SNPs / (2 lineages * 1e6 bp * 1e-9) = 4000 years.
+	if math.Abs(age.Years-4000) > 1e-6 {
+		t.Errorf("got %g years, want 4000", age.Years)
+	}
+	if age.Lower >= age.Years || age.Upper <= age.Years {
+		t.Errorf("interval %g-%g does not contain %g", age.Lower, age.Upper, age.Years)
+	}
+	for _, kit := range tree.Root.Children {
+		if kit.Age != nil {
+			t.Errorf("tip %s has an age", kit.Label)
+		}
+	}
+}
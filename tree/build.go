@@ -0,0 +1,224 @@
+package tree
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Build builds a tree from a presence/absence matrix of kits.
+//
+// Each SNP defines the cluster of kits that are derived for it. SNPs
+// are added to the tree in order of decreasing cluster size. An SNP
+// is placed on a branch if its cluster is compatible with all clusters
+// accepted so far, that means for every accepted cluster both are
+// disjoint or one contains the other. Kits without a call for an SNP
+// may be counted as derived if that makes the SNP compatible with an
+// accepted cluster. SNPs that are not compatible are reported as
+// conflicts. SNPs derived in a single kit are private SNPs of that kit
+// and SNPs derived in all kits are placed on the root branch.
+//
+// Inner branches are labeled with the name of their first named SNP
+// or with B and a number if none of their SNPs has a name.
+func Build(m *snp.Matrix, annotations snp.Annotations) *Tree {
+	t := &Tree{Root: &Node{Label: "root"}, Annotations: annotations}
+	nKits := len(m.Kits)
+	all := newKitSet(nKits)
+	for col := range m.Kits {
+		all.add(col)
+	}
+
+	// Collect the derived and unknown kits of each SNP.
+	type character struct {
+		row              int
+		derived, unknown kitSet
+		size             int
+	}
+	private := make([]snp.SNPs, nKits)
+	var chars []character
+	for row, s := range m.SNPs {
+		c := character{row: row, derived: newKitSet(nKits), unknown: newKitSet(nKits)}
+		last := 0
+		for col := range m.Kits {
+			switch m.Get(row, col) {
+			case snp.Derived:
+				c.derived.add(col)
+				c.size++
+				last = col
+			case snp.Unknown:
+				c.unknown.add(col)
+			}
+		}
+		switch c.size {
+		case 0:
+		case 1:
+			private[last] = append(private[last], s)
+		default:
+			chars = append(chars, c)
+		}
+	}
+	sort.SliceStable(chars, func(i, j int) bool {
+		return chars[i].size > chars[j].size
+	})
+
+	// Accept compatible clusters.
+	type cluster struct {
+		kits kitSet
+		snps snp.SNPs
+	}
+	var clusters []*cluster
+	for _, c := range chars {
+		s := m.SNPs[c.row]
+		kits := c.derived.copy()
+		possible := c.derived.copy()
+		possible.or(c.unknown)
+		for _, cl := range clusters {
+			if cl.kits.intersects(c.derived) && !cl.kits.subsetOf(c.derived) && cl.kits.subsetOf(possible) {
+				kits.or(cl.kits)
+			}
+		}
+		compatible := true
+		var same *cluster
+		for _, cl := range clusters {
+			switch {
+			case cl.kits.equal(kits):
+				same = cl
+			case !cl.kits.intersects(kits), cl.kits.subsetOf(kits), kits.subsetOf(cl.kits):
+			default:
+				compatible = false
+			}
+		}
+		switch {
+		case !compatible:
+			var names []string
+			for col := range m.Kits {
+				if c.derived.has(col) {
+					names = append(names, m.Kits[col])
+				}
+			}
+			t.Conflicts = append(t.Conflicts, Conflict{SNP: s, Kits: names})
+		case kits.equal(all):
+			t.Root.SNPs = append(t.Root.SNPs, s)
+		case same != nil:
+			same.snps = append(same.snps, s)
+		default:
+			clusters = append(clusters, &cluster{kits: kits, snps: snp.SNPs{s}})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].kits.count() > clusters[j].kits.count()
+	})
+	sort.Slice(t.Conflicts, func(i, j int) bool {
+		return t.Conflicts[i].SNP.Less(t.Conflicts[j].SNP)
+	})
+
+	// Link the clusters. Clusters form a hierarchy, so the
+	// smallest cluster containing another is its parent.
+	nodes := make([]*Node, len(clusters))
+	for i, cl := range clusters {
+		nodes[i] = &Node{SNPs: snp.NewSNPs(cl.snps)}
+		parent := t.Root
+		for j := i - 1; j >= 0; j-- {
+			if cl.kits.subsetOf(clusters[j].kits) {
+				parent = nodes[j]
+				break
+			}
+		}
+		parent.AddChild(nodes[i])
+	}
+	for col, kit := range m.Kits {
+		tip := &Node{Label: kit, Kit: kit, SNPs: snp.NewSNPs(private[col])}
+		parent := t.Root
+		for j := len(clusters) - 1; j >= 0; j-- {
+			if clusters[j].kits.has(col) {
+				parent = nodes[j]
+				break
+			}
+		}
+		parent.AddChild(tip)
+	}
+	t.Root.SNPs = snp.NewSNPs(t.Root.SNPs)
+
+	// Label branches.
+	number := 0
+	t.Root.Walk(func(node *Node, depth int) {
+		for _, s := range node.SNPs {
+			if name := annotations[s].Name; name != "" {
+				node.Names = append(node.Names, name)
+			}
+		}
+		if node.Label != "" {
+			return
+		}
+		number++
+		if len(node.Names) > 0 {
+			node.Label = node.Names[0]
+		} else {
+			node.Label = "B" + strconv.Itoa(number)
+		}
+	})
+	return t
+}
+
+// kitSet is a bit set of kit indices.
+type kitSet []uint64
+
+func newKitSet(n int) kitSet {
+	return make(kitSet, (n+63)/64)
+}
+
+func (s kitSet) add(i int) {
+	s[i/64] |= 1 << uint(i%64)
+}
+
+func (s kitSet) has(i int) bool {
+	return s[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (s kitSet) copy() kitSet {
+	return append(kitSet(nil), s...)
+}
+
+func (s kitSet) or(other kitSet) {
+	for i := range s {
+		s[i] |= other[i]
+	}
+}
+
+func (s kitSet) intersects(other kitSet) bool {
+	for i := range s {
+		if s[i]&other[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s kitSet) subsetOf(other kitSet) bool {
+	for i := range s {
+		if s[i]&^other[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s kitSet) equal(other kitSet) bool {
+	for i := range s {
+		if s[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s kitSet) count() int {
+	n := 0
+	for _, word := range s {
+		for ; word != 0; word &= word - 1 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,100 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// kitData describes the calls of a kit for tests.
+type kitData struct {
+	name     string
+	snps     []int
+	callable snp.BEDRegions
+}
+
+// testSNP returns an SNP at pos with fixed alleles.
+func testSNP(pos int) snp.SNP {
+	return snp.SNP{Pos: pos, Ref: "A", Alt: "G"}
+}
+
+// testMatrix creates a matrix from kit data.
+func testMatrix(kits []kitData) *snp.Matrix {
+	names := make([]string, len(kits))
+	sets := make([]snp.SNPs, len(kits))
+	callable := make([]snp.BEDRegions, len(kits))
+	for i, k := range kits {
+		names[i], callable[i] = k.name, k.callable
+		var list []snp.SNP
+		for _, pos := range k.snps {
+			list = append(list, testSNP(pos))
+		}
+		sets[i] = snp.NewSNPs(list)
+	}
+	return snp.NewMatrix(names, sets, callable)
+}
+
+func TestBuild(t *testing.T) {
+	annotations := snp.Annotations{
+		testSNP(100): {Name: "M269"},
+		testSNP(200): {Name: "L21"},
+		testSNP(300): {Name: "U106"},
+	}
+	tests := []struct {
+		name      string
+		kits      []kitData
+		newick    string
+		conflicts []int
+	}{
+		{
+			name: "compatible",
+			kits: []kitData{
+				{name: "a", snps: []int{100, 200, 400}},
+				{name: "b", snps: []int{100, 200}},
+				{name: "c", snps: []int{100, 300}},
+				{name: "d", snps: []int{100, 300}},
+			},
+			newick: "((a:1,b:0)L21:1,(c:0,d:0)U106:1)root:1;",
+		},
+		{
+			name: "incompatible site",
+			kits: []kitData{
+				{name: "a", snps: []int{100, 200}},
+				{name: "b", snps: []int{100, 200, 500}},
+				{name: "c", snps: []int{100, 300, 500}},
+				{name: "d", snps: []int{100, 300}},
+			},
+			newick:    "((a:0,b:0)L21:1,(c:0,d:0)U106:1)root:1;",
+			conflicts: []int{500},
+		},
+		{
+			name: "no call counted as derived",
+			kits: []kitData{
+				{name: "a", snps: []int{200, 600}},
+				{name: "b", snps: []int{200, 600}},
+				{name: "c", snps: []int{200}, callable: snp.BEDRegions{{Start: 0, End: 300}}},
+				{name: "d", snps: []int{700}},
+			},
+			newick: "((a:0,b:0,c:0)L21:2,d:1)root:0;",
+		},
+	}
+	for _, test := range tests {
+		tree := Build(testMatrix(test.kits), annotations)
+		newick, err := tree.newick(false)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if newick != test.newick {
+			t.Errorf("%s: got %s, want %s", test.name, newick, test.newick)
+		}
+		if len(tree.Conflicts) != len(test.conflicts) {
+			t.Errorf("%s: got %d conflicts, want %d", test.name, len(tree.Conflicts), len(test.conflicts))
+			continue
+		}
+		for i, c := range tree.Conflicts {
+			if c.SNP.Pos != test.conflicts[i] {
+				t.Errorf("%s: got conflict %d, want %d", test.name, c.SNP.Pos, test.conflicts[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package tree
+
+import (
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Kinds of homoplasies.
+const (
+	// Parallel means that the SNP occurred independently on several branches.
+	Parallel = "parallel"
+	// Reversion means that the SNP occurred once and was reverted
+	// on some branches below.
+	Reversion = "reversion"
+)
+
+// Homoplasy is an SNP whose pattern across kits is incompatible
+// with the tree.
+type Homoplasy struct {
+	SNP  snp.SNP
+	Kind string
+	// Derived are the kits that are derived for the SNP.
+	Derived []string
+	// Mutations are the branches on which the SNP occurred.
+	Mutations []*Node
+	// Reversions are the branches on which the SNP was reverted.
+	Reversions []*Node
+}
+
+// Homoplasies finds the SNPs of a matrix that are not compatible with
+// the tree. An SNP is compatible if all kits below a single branch are
+// derived or have no call and no other kit is derived. Kits that are
+// not part of the tree are ignored.
+//
+// Incompatible SNPs are explained either by parallel mutations on
+// several branches or by one mutation and reversions on branches below.
+// The explanation with fewer events is chosen. If both need the same
+// number of events, parallel mutations are assumed, because they are
+// more common.
+func (t *Tree) Homoplasies(m *snp.Matrix) []Homoplasy {
+	tips := make(map[string]*Node)
+	t.Root.Walk(func(node *Node, depth int) {
+		if node.Kit != "" {
+			tips[node.Kit] = node
+		}
+	})
+	columns := make(map[*Node]int)
+	for col, kit := range m.Kits {
+		if tip, exists := tips[kit]; exists {
+			columns[tip] = col
+		}
+	}
+
+	var result []Homoplasy
+	derived := make(map[*Node]int)
+	ancestral := make(map[*Node]int)
+	for row, s := range m.SNPs {
+		// Count derived and ancestral kits below each branch.
+		var count func(node *Node)
+		count = func(node *Node) {
+			derived[node], ancestral[node] = 0, 0
+			if col, exists := columns[node]; exists {
+				switch m.Get(row, col) {
+				case snp.Derived:
+					derived[node]++
+				case snp.Ancestral:
+					ancestral[node]++
+				}
+			}
+			for _, c := range node.Children {
+				count(c)
+				derived[node] += derived[c]
+				ancestral[node] += ancestral[c]
+			}
+		}
+		count(t.Root)
+		if derived[t.Root] < 2 {
+			continue
+		}
+
+		// Parallel mutations occur on the highest branches
+		// without ancestral kits.
+		var mutations []*Node
+		var gains func(node *Node)
+		gains = func(node *Node) {
+			if derived[node] == 0 {
+				return
+			}
+			if ancestral[node] == 0 {
+				mutations = append(mutations, node)
+				return
+			}
+			for _, c := range node.Children {
+				gains(c)
+			}
+		}
+		gains(t.Root)
+		if len(mutations) < 2 {
+			continue
+		}
+
+		// A single mutation occurs on the common ancestor of all
+		// derived kits. Reversions occur on the highest branches
+		// below it without derived kits.
+		mrca := t.Root
+		for {
+			var next *Node
+			for _, c := range mrca.Children {
+				if derived[c] == derived[mrca] {
+					next = c
+				}
+			}
+			if next == nil {
+				break
+			}
+			mrca = next
+		}
+		var reversions []*Node
+		var losses func(node *Node)
+		losses = func(node *Node) {
+			if ancestral[node] == 0 {
+				return
+			}
+			if derived[node] == 0 {
+				reversions = append(reversions, node)
+				return
+			}
+			for _, c := range node.Children {
+				losses(c)
+			}
+		}
+		losses(mrca)
+
+		h := Homoplasy{SNP: s, Kind: Parallel, Mutations: mutations}
+		if 1+len(reversions) < len(mutations) {
+			h.Kind, h.Mutations, h.Reversions = Reversion, []*Node{mrca}, reversions
+		}
+		for col, kit := range m.Kits {
+			if m.Get(row, col) == snp.Derived {
+				if _, exists := tips[kit]; exists {
+					h.Derived = append(h.Derived, kit)
+				}
+			}
+		}
+		result = append(result, h)
+	}
+	return result
+}
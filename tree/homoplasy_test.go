@@ -0,0 +1,86 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+func TestHomoplasies(t *testing.T) {
+	// Tree: (((c,d)Z,(e,f)W)Y,(a,b)X).
+	structure := []kitData{
+		{name: "a", snps: []int{200}},
+		{name: "b", snps: []int{200}},
+		{name: "c", snps: []int{300, 400}},
+		{name: "d", snps: []int{300, 400}},
+		{name: "e", snps: []int{300, 500}},
+		{name: "f", snps: []int{300, 500}},
+	}
+	annotations := snp.Annotations{
+		testSNP(200): {Name: "X"},
+		testSNP(300): {Name: "Y"},
+		testSNP(400): {Name: "Z"},
+		testSNP(500): {Name: "W"},
+	}
+	tree := Build(testMatrix(structure), annotations)
+
+	tests := []struct {
+		name       string
+		derived    []string
+		kind       string
+		mutations  []string
+		reversions []string
+	}{
+		{"compatible", []string{"c", "d"}, "", nil, nil},
+		{"private", []string{"a"}, "", nil, nil},
+		{"parallel", []string{"a", "c"}, Parallel, []string{"c", "a"}, nil},
+		{"parallel on branches", []string{"a", "b", "e", "f"}, Parallel, []string{"W", "X"}, nil},
+		{"reversion", []string{"a", "b", "c", "d", "e"}, Reversion, []string{"root"}, []string{"f"}},
+		{"tie is parallel", []string{"a", "b", "c", "d"}, Parallel, []string{"Z", "X"}, nil},
+	}
+	for _, test := range tests {
+		// Add the SNP 900 to the derived kits.
+		kits := make([]kitData, len(structure))
+		copy(kits, structure)
+		for i := range kits {
+			for _, name := range test.derived {
+				if kits[i].name == name {
+					kits[i].snps = append(append([]int(nil), kits[i].snps...), 900)
+				}
+			}
+		}
+		h := tree.Homoplasies(testMatrix(kits))
+		if test.kind == "" {
+			if len(h) != 0 {
+				t.Errorf("%s: got %d homoplasies, want none", test.name, len(h))
+			}
+			continue
+		}
+		if len(h) != 1 {
+			t.Errorf("%s: got %d homoplasies, want 1", test.name, len(h))
+			continue
+		}
+		if h[0].Kind != test.kind {
+			t.Errorf("%s: got %s, want %s", test.name, h[0].Kind, test.kind)
+		}
+		if got := strings.Join(h[0].Derived, ","); got != strings.Join(test.derived, ",") {
+			t.Errorf("%s: derived kits %s, want %s", test.name, got, strings.Join(test.derived, ","))
+		}
+		if got, want := nodeLabels(h[0].Mutations), strings.Join(test.mutations, ","); got != want {
+			t.Errorf("%s: mutations on %s, want %s", test.name, got, want)
+		}
+		if got, want := nodeLabels(h[0].Reversions), strings.Join(test.reversions, ","); got != want {
+			t.Errorf("%s: reversions on %s, want %s", test.name, got, want)
+		}
+	}
+}
+
+// nodeLabels returns the labels of nodes separated by commas.
+func nodeLabels(nodes []*Node) string {
+	labels := make([]string, len(nodes))
+	for i, n := range nodes {
+		labels[i] = n.Label
+	}
+	return strings.Join(labels, ",")
+}
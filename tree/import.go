@@ -0,0 +1,253 @@
+package tree
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// yfullNode is a branch in the YFull YTree JSON export.
+type yfullNode struct {
+	ID       string      `json:"id"`
+	SNPs     string      `json:"snps"`
+	Children []yfullNode `json:"children"`
+}
+
+// ReadYFullJSON reads a tree from the YFull YTree JSON export.
+// Each branch has an id, a list of SNP names separated by commas
+// and a list of children.
+func ReadYFullJSON(r io.Reader) (*Tree, error) {
+	var root yfullNode
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, errors.New(fmt.Sprintf("decoding YFull JSON, %v", err))
+	}
+	return &Tree{Root: root.node()}, nil
+}
+
+func (y *yfullNode) node() *Node {
+	n := &Node{Label: y.ID, Names: splitSNPNames(y.SNPs)}
+	for i := range y.Children {
+		n.AddChild(y.Children[i].node())
+	}
+	return n
+}
+
+// ReadYFullCSV reads a tree from a YFull YTree CSV export.
+// The first line contains the column names. The columns id, parent
+// and snps are used. SNP names are separated by commas. Branches
+// without parent are roots.
+func ReadYFullCSV(r io.Reader) (*Tree, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty YFull CSV file")
+	}
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"id", "parent", "snps"} {
+		if _, exists := columns[name]; !exists {
+			return nil, errors.New(fmt.Sprintf("YFull CSV file has no column %s", name))
+		}
+	}
+	field := func(record []string, name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var ids []string
+	nodes := make(map[string]*Node)
+	parents := make(map[string]string)
+	for _, record := range records[1:] {
+		id := field(record, "id")
+		if id == "" {
+			continue
+		}
+		ids = append(ids, id)
+		nodes[id] = &Node{Label: id, Names: splitSNPNames(field(record, "snps"))}
+		parents[id] = field(record, "parent")
+	}
+	return linkParents(ids, nodes, parents)
+}
+
+// ftdnaHaplotree is the FTDNA public haplotree JSON export.
+type ftdnaHaplotree struct {
+	AllNodes map[string]ftdnaNode `json:"allNodes"`
+}
+
+// ftdnaNode is a branch in the FTDNA haplotree.
+type ftdnaNode struct {
+	HaplogroupID int    `json:"haplogroupId"`
+	ParentID     int    `json:"parentId"`
+	Name         string `json:"name"`
+	Variants     []struct {
+		Variant string `json:"variant"`
+	} `json:"variants"`
+}
+
+// ReadFTDNA reads a tree from the FTDNA public haplotree JSON export.
+// The branches are listed in allNodes with their haplogroup ids,
+// parent ids, names and variants.
+func ReadFTDNA(r io.Reader) (*Tree, error) {
+	var haplotree ftdnaHaplotree
+	if err := json.NewDecoder(r).Decode(&haplotree); err != nil {
+		return nil, errors.New(fmt.Sprintf("decoding FTDNA JSON, %v", err))
+	}
+	if len(haplotree.AllNodes) == 0 {
+		return nil, errors.New("FTDNA haplotree contains no branches")
+	}
+	list := make([]ftdnaNode, 0, len(haplotree.AllNodes))
+	for _, f := range haplotree.AllNodes {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].HaplogroupID < list[j].HaplogroupID })
+	var ids []string
+	nodes := make(map[string]*Node)
+	parents := make(map[string]string)
+	for _, f := range list {
+		id := strconv.Itoa(f.HaplogroupID)
+		n := &Node{Label: f.Name}
+		for _, v := range f.Variants {
+			if name := strings.TrimSpace(v.Variant); name != "" {
+				n.Names = append(n.Names, name)
+			}
+		}
+		ids = append(ids, id)
+		nodes[id] = n
+		if f.ParentID != 0 {
+			parents[id] = strconv.Itoa(f.ParentID)
+		}
+	}
+	return linkParents(ids, nodes, parents)
+}
+
+// linkParents builds a tree from nodes and their parent ids.
+// Children keep the order of ids. Nodes whose parents are unknown
+// are roots. If there are several roots, they become children
+// of a new root. Nodes that are their own parents are roots, too.
+// An error is returned if parent ids form a cycle.
+func linkParents(ids []string, nodes map[string]*Node, parents map[string]string) (*Tree, error) {
+	for id, parent := range parents {
+		if parent == id {
+			delete(parents, id)
+		}
+	}
+
+	// Follow the parents of each node until a root or a node
+	// that is already checked is reached.
+	checked := make(map[string]bool)
+	for _, id := range ids {
+		onPath := make(map[string]int)
+		var path []string
+		for p := id; !checked[p]; p = parents[p] {
+			if _, exists := nodes[p]; !exists {
+				break
+			}
+			if i, exists := onPath[p]; exists {
+				return nil, errors.New(fmt.Sprintf("parent ids form a cycle: %s", strings.Join(path[i:], ", ")))
+			}
+			onPath[p] = len(path)
+			path = append(path, p)
+		}
+		for _, p := range path {
+			checked[p] = true
+		}
+	}
+
+	var roots []*Node
+	for _, id := range ids {
+		n := nodes[id]
+		if parent, exists := nodes[parents[id]]; exists {
+			parent.AddChild(n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	if len(roots) == 1 {
+		return &Tree{Root: roots[0]}, nil
+	}
+	root := &Node{Label: "root"}
+	for _, n := range roots {
+		root.AddChild(n)
+	}
+	return &Tree{Root: root}, nil
+}
+
+// splitSNPNames splits a list of SNP names separated by commas.
+// Synonyms separated by slashes are kept together.
+func splitSNPNames(names string) []string {
+	var result []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// Link looks up the SNP names of all branches in the data base and
+// adds the SNPs to the branches. The names become the annotations
+// of the SNPs. Names of a branch that denote the same SNP are joined
+// by slashes. Names that could not be resolved or that are ambiguous
+// are returned.
+func (t *Tree) Link(db *snp.DB) (unresolved []string) {
+	if t.Annotations == nil {
+		t.Annotations = make(snp.Annotations)
+	}
+	t.Root.Walk(func(node *Node, depth int) {
+		var snps []snp.SNP
+		var names []string
+		synonyms := make(map[snp.SNP]int)
+		for _, name := range node.Names {
+			entries := db.Resolve(name)
+			if len(entries) != 1 {
+				unresolved = append(unresolved, name)
+				names = append(names, name)
+				continue
+			}
+			key := entries[0].Key
+			if i, exists := synonyms[key]; exists {
+				names[i] = joinSynonyms(names[i], name)
+			} else {
+				synonyms[key] = len(names)
+				names = append(names, name)
+				snps = append(snps, key)
+			}
+		}
+		for key, i := range synonyms {
+			t.Annotations.Add(key, snp.Annotation{Name: names[i]})
+		}
+		node.Names = names
+		node.SNPs = snp.NewSNPs(append(snps, node.SNPs...))
+	})
+	return unresolved
+}
+
+// joinSynonyms joins two names with a slash,
+// omitting synonyms that are already contained in a.
+func joinSynonyms(a, b string) string {
+	for _, n := range splitName(b) {
+		found := false
+		for _, m := range splitName(a) {
+			found = found || m == n
+		}
+		if !found {
+			a += "/" + n
+		}
+	}
+	return a
+}
@@ -0,0 +1,146 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+func TestReadYFullCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		csv    string
+		newick string
+		err    string
+	}{
+		{
+			name:   "tree",
+			csv:    "id,parent,snps\nA,,M1\nB,A,M2\nC,A,M3\n",
+			newick: "(B:1,C:1)A:1;",
+		},
+		{
+			name:   "several roots",
+			csv:    "id,parent,snps\nA,,M1\nB,X,M2\n",
+			newick: "(A:1,B:1)root:0;",
+		},
+		{
+			name:   "own parent",
+			csv:    "id,parent,snps\nA,A,M1\nB,A,M2\n",
+			newick: "(B:1)A:1;",
+		},
+		{
+			name: "cycle",
+			csv:  "id,parent,snps\nA,,M1\nB,A,M2\nC,E,M3\nD,C,M4\nE,D,M5\n",
+			err:  "C, E, D",
+		},
+	}
+	for _, test := range tests {
+		tree, err := ReadYFullCSV(strings.NewReader(test.csv))
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got, _ := tree.newick(false); got != test.newick {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.newick)
+		}
+	}
+}
+
+func TestReadYFullJSON(t *testing.T) {
+	ytree := `{"id":"R-M269","snps":"M269/S3","children":[
+		{"id":"R-L21","snps":"L21/S145, Y999","children":[{"id":"R-DF13","snps":"DF13","children":[]}]},
+		{"id":"R-Z1","snps":"Z1","children":[]}]}`
+	tree, err := ReadYFullJSON(strings.NewReader(ytree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := tree.newick(false); got != "((R-DF13:1)R-L21:2,R-Z1:1)R-M269:1;" {
+		t.Errorf("got %s", got)
+	}
+	if got := strings.Join(tree.Root.Children[0].Names, " "); got != "L21/S145 Y999" {
+		t.Errorf("got names %s, want L21/S145 Y999", got)
+	}
+	if _, err := ReadYFullJSON(strings.NewReader("{")); err == nil {
+		t.Error("missing error for invalid JSON")
+	}
+}
+
+func TestReadFTDNA(t *testing.T) {
+	tests := []struct {
+		name   string
+		json   string
+		newick string
+		err    bool
+	}{
+		{
+			name: "haplotree",
+			json: `{"allNodes":{"12":{"haplogroupId":12,"parentId":10,"name":"R-L21","variants":[{"variant":"L21"},{"variant":"S145"}]},
+				"10":{"haplogroupId":10,"parentId":0,"name":"R-M269","variants":[{"variant":"M269"}]},
+				"11":{"haplogroupId":11,"parentId":10,"name":"R-U106","variants":[{"variant":" U106 "},{"variant":""}]}}}`,
+			newick: "(R-U106:1,R-L21:2)R-M269:1;",
+		},
+		{name: "no branches", json: `{"allNodes":{}}`, err: true},
+		{
+			name: "cycle",
+			json: `{"allNodes":{"1":{"haplogroupId":1,"parentId":2,"name":"A"},"2":{"haplogroupId":2,"parentId":1,"name":"B"}}}`,
+			err:  true,
+		},
+	}
+	for _, test := range tests {
+		tree, err := ReadFTDNA(strings.NewReader(test.json))
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: missing error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got, _ := tree.newick(false); got != test.newick {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.newick)
+		}
+		if got := strings.Join(tree.Root.Children[0].Names, " "); got != "U106" {
+			t.Errorf("%s: got names %s, want U106", test.name, got)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	db := snp.NewDB()
+	for _, r := range []snp.DBRecord{
+		{Key: snp.SNP{Pos: 100, Ref: "C", Alt: "T"}, Name: "M269"},
+		{Key: snp.SNP{Pos: 100, Ref: "C", Alt: "T"}, Name: "S3"},
+		{Key: snp.SNP{Pos: 200, Ref: "C", Alt: "G"}, Name: "L21"},
+		{Key: snp.SNP{Pos: 7000000, Ref: "A", Alt: "T"}, Name: "Z1"},
+		{Key: snp.SNP{Pos: 9000000, Ref: "G", Alt: "C"}, Name: "Z1"},
+	} {
+		db.Add(r)
+	}
+	tree, err := ReadYFullCSV(strings.NewReader("id,parent,snps\nR-M269,,\"M269, S3\"\nR-L21,R-M269,\"L21, Z1, X9\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unresolved := tree.Link(db)
+	if got := strings.Join(unresolved, " "); got != "Z1 X9" {
+		t.Errorf("got unresolved %s, want Z1 X9", got)
+	}
+	root, l21 := tree.Root, tree.Root.Children[0]
+	if len(root.SNPs) != 1 || root.SNPs[0].Pos != 100 || tree.Annotations[root.SNPs[0]].Name != "M269/S3" {
+		t.Errorf("got root SNPs %v with annotations %v", root.SNPs, tree.Annotations)
+	}
+	if len(l21.SNPs) != 1 || l21.SNPs[0].Pos != 200 {
+		t.Errorf("got L21 SNPs %v", l21.SNPs)
+	}
+	if got := strings.Join(l21.Names, " "); got != "L21 Z1 X9" {
+		t.Errorf("got L21 names %s", got)
+	}
+}
@@ -0,0 +1,308 @@
+package tree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteNewick writes the tree in Newick format. Branch labels are
+// the node labels and branch lengths are the numbers of SNPs.
+// If normalized is true, the branch lengths are the numbers of SNPs
+// divided by the callable lengths of the branches.
+func (t *Tree) WriteNewick(w io.Writer, normalized bool) error {
+	s, err := t.newick(normalized)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(s + "\r\n")
+	return bw.Flush()
+}
+
+// WriteNEXUS writes the tree in NEXUS format with a taxa block
+// and a trees block. The branch lengths are the same as for
+// WriteNewick.
+func (t *Tree) WriteNEXUS(w io.Writer, normalized bool) error {
+	s, err := t.newick(normalized)
+	if err != nil {
+		return err
+	}
+	var taxa []string
+	t.Root.Walk(func(node *Node, depth int) {
+		if node.IsTip() {
+			taxa = append(taxa, newickLabel(node.Label))
+		}
+	})
+	bw := bufio.NewWriter(w)
+	bw.WriteString("#NEXUS\r\n")
+	bw.WriteString("BEGIN TAXA;\r\n")
+	fmt.Fprintf(bw, "\tDIMENSIONS NTAX=%d;\r\n", len(taxa))
+	bw.WriteString("\tTAXLABELS " + strings.Join(taxa, " ") + ";\r\n")
+	bw.WriteString("END;\r\n")
+	bw.WriteString("BEGIN TREES;\r\n")
+	bw.WriteString("\tTREE phylosnip = " + s + "\r\n")
+	bw.WriteString("END;\r\n")
+	return bw.Flush()
+}
+
+// newick returns the tree as a Newick string terminated by a semicolon.
+func (t *Tree) newick(normalized bool) (string, error) {
+	var b strings.Builder
+	if err := t.Root.newick(&b, normalized); err != nil {
+		return "", err
+	}
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+func (n *Node) newick(b *strings.Builder, normalized bool) error {
+	if len(n.Children) > 0 {
+		b.WriteString("(")
+		for i, c := range n.Children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if err := c.newick(b, normalized); err != nil {
+				return err
+			}
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(newickLabel(n.Label))
+	length := float64(n.SNPCount())
+	if length == 0 && n.Length != 0 {
+		length = n.Length
+	}
+	if normalized {
+		if n.Callable == 0 {
+			return errors.New(fmt.Sprintf("callable length of branch %s is unknown", n.Label))
+		}
+		length /= float64(n.Callable)
+	}
+	b.WriteString(":" + strconv.FormatFloat(length, 'g', -1, 64))
+	return nil
+}
+
+// newickLabel quotes a label if it contains characters
+// that have a special meaning in Newick format. Underscores
+// are quoted, because they denote spaces in unquoted labels.
+func newickLabel(label string) string {
+	if !strings.ContainsAny(label, " \t\r\n()[]':;,_") {
+		return label
+	}
+	return "'" + strings.Replace(label, "'", "''", -1) + "'"
+}
+
+// ReadNewick reads a tree in Newick format. Tips get the kit name
+// of their label. Branch lengths are stored in the Length field of
+// the nodes. Underscores in unquoted labels are read as spaces.
+// If the input is in NEXUS format, the first tree of the trees block
+// is read and the translation table of the block is applied to the
+// labels of the tips.
+func ReadNewick(r io.Reader) (*Tree, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+	var translate map[string]string
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(text)), "#NEXUS") {
+		text, translate, err = nexusTree(text)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p := &newickParser{text: text}
+	root, err := p.node()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos >= len(p.text) || p.text[p.pos] != ';' {
+		return nil, p.errorf("missing ;")
+	}
+	root.Walk(func(node *Node, depth int) {
+		if node.IsTip() {
+			if label, exists := translate[node.Label]; exists {
+				node.Label = label
+			}
+			node.Kit = node.Label
+		}
+	})
+	return &Tree{Root: root}, nil
+}
+
+// nexusTree returns the Newick string of the first tree
+// in the trees block of a NEXUS file and the translation
+// table of the block, which may be nil.
+func nexusTree(text string) (tree string, translate map[string]string, err error) {
+	upper := strings.ToUpper(text)
+	block := strings.Index(upper, "BEGIN TREES;")
+	if block < 0 {
+		return "", nil, errors.New("NEXUS file contains no trees block")
+	}
+	block += len("BEGIN TREES;")
+	start := strings.Index(upper[block:], "TREE ")
+	if t := strings.Index(upper[block:], "TRANSLATE"); t >= 0 && (start < 0 || t < start) {
+		p := &newickParser{text: text[block+t+len("TRANSLATE"):]}
+		translate, err = p.translation()
+		if err != nil {
+			return "", nil, errors.New(fmt.Sprintf("NEXUS translate, %v", err))
+		}
+		block += t + len("TRANSLATE") + p.pos
+		start = strings.Index(upper[block:], "TREE ")
+	}
+	if start < 0 {
+		return "", nil, errors.New("NEXUS trees block contains no tree")
+	}
+	start += block
+	eq := strings.Index(text[start:], "=")
+	if eq < 0 {
+		return "", nil, errors.New("missing = in NEXUS tree")
+	}
+	return text[start+eq+1:], translate, nil
+}
+
+// newickParser is a recursive descent parser for Newick trees.
+//
+//	node   = [ "(" node { "," node } ")" ] [ label ] [ ":" length ]
+type newickParser struct {
+	text string
+	pos  int
+}
+
+func (p *newickParser) errorf(format string, args ...interface{}) error {
+	return errors.New(fmt.Sprintf("Newick position %d: ", p.pos) + fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips white space and comments in square brackets.
+func (p *newickParser) skipSpace() {
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case c == '[':
+			end := strings.IndexByte(p.text[p.pos:], ']')
+			if end < 0 {
+				p.pos = len(p.text)
+			} else {
+				p.pos += end + 1
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *newickParser) node() (*Node, error) {
+	n := &Node{}
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == '(' {
+		p.pos++
+		for {
+			child, err := p.node()
+			if err != nil {
+				return nil, err
+			}
+			n.AddChild(child)
+			p.skipSpace()
+			if p.pos >= len(p.text) {
+				return nil, p.errorf("unexpected end of tree")
+			}
+			if p.text[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+			if p.text[p.pos] != ')' {
+				return nil, p.errorf("unexpected %q", p.text[p.pos])
+			}
+			p.pos++
+			break
+		}
+	}
+	label, err := p.label()
+	if err != nil {
+		return nil, err
+	}
+	n.Label = label
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == ':' {
+		p.pos++
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.text) && strings.IndexByte("0123456789+-.eE", p.text[p.pos]) >= 0 {
+			p.pos++
+		}
+		n.Length, err = strconv.ParseFloat(p.text[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid branch length %q", p.text[start:p.pos])
+		}
+	}
+	return n, nil
+}
+
+// translation reads the entries of a NEXUS translate command,
+// which are pairs of keys and labels separated by commas and
+// terminated by a semicolon.
+func (p *newickParser) translation() (map[string]string, error) {
+	translate := make(map[string]string)
+	for {
+		key, err := p.label()
+		if err != nil {
+			return nil, err
+		}
+		label, err := p.label()
+		if err != nil {
+			return nil, err
+		}
+		if key == "" || label == "" {
+			return nil, p.errorf("missing key or label")
+		}
+		translate[key] = label
+		p.skipSpace()
+		if p.pos >= len(p.text) {
+			return nil, p.errorf("missing ;")
+		}
+		switch p.text[p.pos] {
+		case ',':
+			p.pos++
+		case ';':
+			p.pos++
+			return translate, nil
+		default:
+			return nil, p.errorf("unexpected %q", p.text[p.pos])
+		}
+	}
+}
+
+// label reads an optional quoted or unquoted label.
+// Underscores in unquoted labels are replaced by spaces.
+func (p *newickParser) label() (string, error) {
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == '\'' {
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.text); p.pos++ {
+			if p.text[p.pos] != '\'' {
+				b.WriteByte(p.text[p.pos])
+				continue
+			}
+			if p.pos+1 < len(p.text) && p.text[p.pos+1] == '\'' {
+				b.WriteByte('\'')
+				p.pos++
+				continue
+			}
+			p.pos++
+			return b.String(), nil
+		}
+		return "", p.errorf("unterminated quoted label")
+	}
+	start := p.pos
+	for p.pos < len(p.text) && strings.IndexByte(" \t\r\n()[]':;,", p.text[p.pos]) < 0 {
+		p.pos++
+	}
+	return strings.Replace(p.text[start:p.pos], "_", " ", -1), nil
+}
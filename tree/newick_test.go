@@ -0,0 +1,117 @@
+package tree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewickRoundTrip(t *testing.T) {
+	tests := []string{
+		"(a:1,b:2)root:0;",
+		"((a:1,b:2)X:3,'c d':0.5)root:1;",
+		"(((k1:1,k2:1)DF13:2,k3:1)L21:1,(k4:0,k5:0)U106:1)root:1;",
+		"('it''s':1,'x,y':2)'R M269':4;",
+		"('kit_1':1,'kit 2':2)root:0;",
+	}
+	for _, test := range tests {
+		tree, err := ReadNewick(strings.NewReader(test))
+		if err != nil {
+			t.Errorf("%s: %v", test, err)
+			continue
+		}
+		var newick, nexus bytes.Buffer
+		if err := tree.WriteNewick(&newick, false); err != nil {
+			t.Errorf("%s: %v", test, err)
+			continue
+		}
+		if got := strings.TrimSpace(newick.String()); got != test {
+			t.Errorf("got %s, want %s", got, test)
+		}
+
+		// NEXUS files contain the same tree.
+		if err := tree.WriteNEXUS(&nexus, false); err != nil {
+			t.Errorf("%s: %v", test, err)
+			continue
+		}
+		tree, err = ReadNewick(&nexus)
+		if err != nil {
+			t.Errorf("%s: reading NEXUS, %v", test, err)
+			continue
+		}
+		got, _ := tree.newick(false)
+		if got != test {
+			t.Errorf("NEXUS: got %s, want %s", got, test)
+		}
+	}
+}
+
+func TestReadNewick(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		newick string
+		kits   string
+	}{
+		{
+			name:   "comments and white space",
+			input:  "( a [kit a] : 1 ,\r\n b:2 ) root ;",
+			newick: "(a:1,b:2)root:0;",
+			kits:   "a,b",
+		},
+		{
+			name:   "underscores",
+			input:  "(kit_1:1,'kit_2':2)R_M269:0;",
+			newick: "('kit 1':1,'kit_2':2)'R M269':0;",
+			kits:   "kit 1,kit_2",
+		},
+		{
+			name: "NEXUS translate",
+			input: "#NEXUS\r\nBegin Trees;\r\n\tTranslate\r\n\t\t1 kitA,\r\n\t\t2 'kit B',\r\n\t\t3 kit_C\r\n\t;\r\n" +
+				"\ttree one = ((1:1,2:1)L21:2,3:1)root;\r\nEnd;\r\n",
+			newick: "((kitA:1,'kit B':1)L21:2,'kit C':1)root:0;",
+			kits:   "kitA,kit B,kit C",
+		},
+		{
+			name:   "NEXUS without translate",
+			input:  "#NEXUS\r\nBEGIN TREES;\r\n\tTREE t = (1:1,2:1)root;\r\nEND;\r\n",
+			newick: "(1:1,2:1)root:0;",
+			kits:   "1,2",
+		},
+	}
+	for _, test := range tests {
+		tree, err := ReadNewick(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got, _ := tree.newick(false); got != test.newick {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.newick)
+		}
+		var kits []string
+		tree.Root.Walk(func(node *Node, depth int) {
+			if node.IsTip() {
+				kits = append(kits, node.Kit)
+			}
+		})
+		if got := strings.Join(kits, ","); got != test.kits {
+			t.Errorf("%s: got kits %s, want %s", test.name, got, test.kits)
+		}
+	}
+}
+
+func TestReadNewickErrors(t *testing.T) {
+	tests := []string{
+		"(a:1,b:2)root:0",
+		"(a:1,b:2:0;",
+		"(a:x)root;",
+		"#NEXUS\r\nBEGIN TREES;\r\n\tTRANSLATE 1 a, 2;\r\n\tTREE t = (1,2);\r\nEND;\r\n",
+		"#NEXUS\r\nBEGIN TREES;\r\n\tTRANSLATE 1 a, 2 b\r\n\tTREE t = (1,2);\r\nEND;\r\n",
+		"#NEXUS\r\nBEGIN TAXA;\r\nEND;\r\n",
+	}
+	for _, test := range tests {
+		if _, err := ReadNewick(strings.NewReader(test)); err == nil {
+			t.Errorf("%s: missing error", test)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package tree
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Calls contains the SNP calls of a kit.
+type Calls struct {
+	// Derived are the SNPs called for the kit. If DB is nil, they are
+	// the SNPs for which the kit is positive. Otherwise the called
+	// alleles are polarized using DB.
+	Derived snp.SNPs
+	// Ancestral are the SNPs for which the kit is negative.
+	Ancestral snp.SNPs
+	// Callable are the callable regions of the kit or nil if they
+	// are unknown. Positions in callable regions without a call
+	// carry the reference allele.
+	Callable snp.BEDRegions
+	// DB is the data base used to polarize the calls or nil.
+	DB *snp.DB
+	// calls maps positions to the called SNPs.
+	calls map[int]snp.SNP
+	// derivedNames and ancestralNames are the names of the SNPs.
+	// They are used for branches whose SNP positions are unknown.
+	derivedNames   map[string]bool
+	ancestralNames map[string]bool
+}
+
+// NewCalls creates the calls of a kit. annotations contains the
+// names of the derived and ancestral SNPs. db is used to polarize
+// the calls and may be nil.
+func NewCalls(derived, ancestral snp.SNPs, annotations snp.Annotations, callable snp.BEDRegions, db *snp.DB) *Calls {
+	c := &Calls{
+		Derived:        derived,
+		Ancestral:      ancestral,
+		DB:             db,
+		calls:          make(map[int]snp.SNP),
+		derivedNames:   make(map[string]bool),
+		ancestralNames: make(map[string]bool)}
+	if callable != nil {
+		c.Callable = append(c.Callable, callable...)
+		c.Callable.Normalize()
+	}
+	for _, s := range derived {
+		c.calls[s.Pos] = s
+		// Calls at positions of the data base are polarized.
+		names := c.derivedNames
+		if db != nil {
+			if _, exists := db.EntryByPosition(s.Pos); exists {
+				switch polarity, _ := db.Polarize(s); polarity {
+				case snp.Ancestral:
+					names = c.ancestralNames
+				case snp.Unknown:
+					continue
+				}
+			}
+		}
+		for _, name := range splitName(annotations[s].Name) {
+			names[name] = true
+		}
+	}
+	for _, s := range ancestral {
+		for _, name := range splitName(annotations[s].Name) {
+			c.ancestralNames[name] = true
+		}
+	}
+	return c
+}
+
+// State returns the state of the kit for an SNP of the tree.
+//
+// The called allele is derived if it is the derived allele of the
+// SNP and ancestral if it is the ancestral allele. If the data base
+// contains the SNP, its alleles are used. Otherwise the SNP is assumed
+// to have the ancestral allele as Ref and the derived allele as Alt,
+// like the SNPs of trees built from kits.
+//
+// Positions in callable regions without a call carry the reference
+// allele. Because the reference may carry the derived allele, their
+// state is unknown for SNPs that are in the data base. For other SNPs
+// the reference allele is assumed to be Ref.
+func (c *Calls) State(s snp.SNP) snp.Polarity {
+	if c.Ancestral.Contains(s) {
+		return snp.Ancestral
+	}
+	ancestral, derived, polarized := s.Ref, s.Alt, false
+	if c.DB != nil {
+		if recs, exists := c.DB.EntryByPosition(s.Pos); exists {
+			for _, rec := range recs {
+				if rec.Key == s || rec.Key.Ref == s.Alt && rec.Key.Alt == s.Ref {
+					ancestral, derived, polarized = rec.Key.Ref, rec.Key.Alt, true
+					break
+				}
+			}
+		}
+	}
+	if call, exists := c.calls[s.Pos]; exists {
+		switch call.Alt {
+		case derived:
+			return snp.Derived
+		case ancestral:
+			return snp.Ancestral
+		}
+		return snp.Unknown
+	}
+	if c.Callable != nil && c.Callable.Includes(s.Pos) && !polarized {
+		return snp.Ancestral
+	}
+	return snp.Unknown
+}
+
+// NameState returns the state of the kit for an SNP name.
+// Names containing synonyms separated by slashes are
+// derived or ancestral if one of the synonyms is.
+func (c *Calls) NameState(name string) snp.Polarity {
+	state := snp.Unknown
+	for _, n := range splitName(name) {
+		switch {
+		case c.derivedNames[n]:
+			return snp.Derived
+		case c.ancestralNames[n]:
+			state = snp.Ancestral
+		}
+	}
+	return state
+}
+
+// splitName splits a name into synonyms separated by slashes.
+func splitName(name string) []string {
+	if name == "" {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
+
+// BranchCalls contains the states of a kit for the SNPs of a branch.
+// SNPs are given by name or, if they have no name, as Pos,Ref,Alt.
+type BranchCalls struct {
+	Node      *Node
+	Derived   []string
+	Ancestral []string
+	Unknown   []string
+}
+
+// Called returns the number of SNPs that are derived or ancestral.
+func (b *BranchCalls) Called() int {
+	return len(b.Derived) + len(b.Ancestral)
+}
+
+// Placement is the position of a kit in a tree.
+type Placement struct {
+	// Node is the deepest branch consistent with the calls.
+	Node *Node
+	// Path contains the calls for all branches from the root to Node.
+	Path []BranchCalls
+	// OffPath contains the calls for branches outside of the path
+	// for which the kit has derived SNPs.
+	OffPath []BranchCalls
+	// Consistency is the fraction of derived SNPs on the path among
+	// all called SNPs on the path and derived SNPs outside of it.
+	Consistency float64
+	// Coverage is the fraction of SNPs of the terminal branch that are called.
+	Coverage float64
+	// Downstream is the fraction of the child branches of Node that
+	// are excluded by ancestral calls. Only child branches with called
+	// SNPs are counted. A low value means that the kit may belong
+	// to a deeper branch. If there are no such child branches,
+	// for example for tips, Downstream is not applicable and NaN.
+	Downstream float64
+	// Confidence is the product of Consistency, Coverage and, if it
+	// is applicable, Downstream.
+	Confidence float64
+}
+
+// Place finds the position of a kit in the tree.
+//
+// The score of a branch is the number of derived minus the number of
+// ancestral calls on the path from the root to the branch. The kit is
+// placed on the branch with the highest score among all branches with
+// at least one derived call. If several branches have the same score,
+// the deepest is chosen. Branches whose SNP positions are known are
+// evaluated by position, other branches by SNP name. Inner branches
+// without SNPs and names, for example from Newick files, are evaluated
+// by their labels.
+func (t *Tree) Place(c *Calls) *Placement {
+	calls := make(map[*Node]BranchCalls)
+	t.Root.Walk(func(node *Node, depth int) {
+		calls[node] = t.branchCalls(node, c)
+	})
+
+	// Find the best branch.
+	best, bestScore, bestDepth := t.Root, 0, 0
+	scores := make(map[*Node]int)
+	t.Root.Walk(func(node *Node, depth int) {
+		bc := calls[node]
+		score := len(bc.Derived) - len(bc.Ancestral)
+		if node.Parent != nil {
+			score += scores[node.Parent]
+		}
+		scores[node] = score
+		if len(bc.Derived) == 0 {
+			return
+		}
+		if score > bestScore || score == bestScore && depth > bestDepth {
+			best, bestScore, bestDepth = node, score, depth
+		}
+	})
+
+	// Collect calls.
+	p := &Placement{Node: best}
+	onPath := make(map[*Node]bool)
+	derived, ancestral, offPath := 0, 0, 0
+	for _, node := range best.Path() {
+		onPath[node] = true
+		bc := calls[node]
+		p.Path = append(p.Path, bc)
+		derived += len(bc.Derived)
+		ancestral += len(bc.Ancestral)
+	}
+	t.Root.Walk(func(node *Node, depth int) {
+		if bc := calls[node]; !onPath[node] && len(bc.Derived) > 0 {
+			p.OffPath = append(p.OffPath, bc)
+			offPath += len(bc.Derived)
+		}
+	})
+
+	// Calculate confidence.
+	p.Consistency = 1
+	if derived+ancestral+offPath > 0 {
+		p.Consistency = float64(derived) / float64(derived+ancestral+offPath)
+	}
+	p.Coverage = 1
+	if bc := calls[best]; len(bc.Unknown) > 0 {
+		p.Coverage = float64(bc.Called()) / float64(bc.Called()+len(bc.Unknown))
+	}
+	excluded, children := 0, 0
+	for _, child := range best.Children {
+		bc := calls[child]
+		if bc.Called() == 0 {
+			continue
+		}
+		children++
+		if len(bc.Ancestral) > 0 {
+			excluded++
+		}
+	}
+	p.Confidence = p.Consistency * p.Coverage
+	if children > 0 {
+		p.Downstream = float64(excluded) / float64(children)
+		p.Confidence *= p.Downstream
+	} else {
+		p.Downstream = math.NaN()
+	}
+	return p
+}
+
+// branchCalls returns the states of the kit for the SNPs of a branch.
+func (t *Tree) branchCalls(n *Node, c *Calls) BranchCalls {
+	bc := BranchCalls{Node: n}
+	add := func(state snp.Polarity, marker string) {
+		switch state {
+		case snp.Derived:
+			bc.Derived = append(bc.Derived, marker)
+		case snp.Ancestral:
+			bc.Ancestral = append(bc.Ancestral, marker)
+		default:
+			bc.Unknown = append(bc.Unknown, marker)
+		}
+	}
+	switch {
+	case len(n.SNPs) > 0:
+		for _, s := range n.SNPs {
+			add(c.State(s), t.marker(s))
+		}
+	case len(n.Names) > 0:
+		for _, name := range n.Names {
+			add(c.NameState(name), name)
+		}
+	case n.Label != "" && n.Kit == "" && n.Parent != nil:
+		add(c.NameState(n.Label), n.Label)
+	}
+	return bc
+}
+
+// marker returns the name of an SNP or Pos,Ref,Alt if it has no name.
+func (t *Tree) marker(s snp.SNP) string {
+	if name := t.Annotations[s].Name; name != "" {
+		return name
+	}
+	return strconv.Itoa(s.Pos) + "," + s.Ref + "," + s.Alt
+}
+
+// Check is the result of checking a kit against a tree.
+type Check struct {
+	Placement *Placement
+	// Splits are inner branches for which the kit is derived for
+	// some SNPs and ancestral for others. They would be split
+	// if the kit was added to the tree.
+	Splits []BranchCalls
+	// Conflicts are branches on the path for which the kit is only
+	// ancestral and inner branches outside of the path for which
+	// the kit is derived.
+	Conflicts []BranchCalls
+	// SharedPrivates are the tips of kits whose private SNPs
+	// are derived in the checked kit.
+	SharedPrivates []BranchCalls
+	// Novel are the derived SNPs of the kit that are neither on
+	// a branch of the tree nor conflicts of the tree. Calls that
+	// are ancestral according to the data base of the calls are
+	// not novel.
+	Novel snp.SNPs
+}
+
+// Check checks whether a kit fits into the tree. The kit is placed
+// like Place does. Then all branches are checked for calls that
+// would change the structure of the tree.
+func (t *Tree) Check(c *Calls) *Check {
+	p := t.Place(c)
+	result := &Check{Placement: p}
+	onPath := make(map[*Node]bool)
+	for _, bc := range p.Path {
+		onPath[bc.Node] = true
+	}
+	known := make([]snp.SNP, 0, len(t.Conflicts))
+	for _, conflict := range t.Conflicts {
+		known = append(known, conflict.SNP)
+	}
+	t.Root.Walk(func(node *Node, depth int) {
+		known = append(known, node.SNPs...)
+		bc := t.branchCalls(node, c)
+		switch {
+		case node.Kit != "":
+			if len(bc.Derived) > 0 {
+				result.SharedPrivates = append(result.SharedPrivates, bc)
+			}
+		case len(bc.Derived) > 0 && len(bc.Ancestral) > 0:
+			result.Splits = append(result.Splits, bc)
+		case onPath[node] && len(bc.Derived) == 0 && len(bc.Ancestral) > 0:
+			result.Conflicts = append(result.Conflicts, bc)
+		case !onPath[node] && len(bc.Derived) > 0:
+			result.Conflicts = append(result.Conflicts, bc)
+		}
+	})
+	result.Novel = c.Derived.Copy()
+	result.Novel.Difference(snp.NewSNPs(known))
+	if c.DB != nil {
+		result.Novel.Filter(func(s snp.SNP) bool {
+			polarity, _ := c.DB.Polarize(s)
+			return polarity != snp.Ancestral
+		})
+	}
+	return result
+}
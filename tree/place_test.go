@@ -0,0 +1,231 @@
+package tree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// everywhere are callable regions covering all test positions.
+var everywhere = snp.BEDRegions{{Start: 0, End: math.MaxInt32}}
+
+// placeTree returns a tree with the branches L21 (kits a, b)
+// and U106 (kits c, d). Kit a has the private SNP 400.
+func placeTree() *Tree {
+	annotations := snp.Annotations{
+		testSNP(100): {Name: "M269"},
+		testSNP(200): {Name: "L21"},
+		testSNP(300): {Name: "U106"},
+	}
+	return Build(testMatrix([]kitData{
+		{name: "a", snps: []int{100, 200, 400}},
+		{name: "b", snps: []int{100, 200}},
+		{name: "c", snps: []int{100, 300}},
+		{name: "d", snps: []int{100, 300}},
+	}), annotations)
+}
+
+// testSNPs returns SNPs at the given positions.
+func testSNPs(positions ...int) snp.SNPs {
+	var list []snp.SNP
+	for _, pos := range positions {
+		list = append(list, testSNP(pos))
+	}
+	return snp.NewSNPs(list)
+}
+
+func TestPlace(t *testing.T) {
+	na := math.NaN()
+	tests := []struct {
+		name        string
+		derived     snp.SNPs
+		callable    snp.BEDRegions
+		node        string
+		consistency float64
+		downstream  float64
+		confidence  float64
+	}{
+		{"branch", testSNPs(100, 200), everywhere, "L21", 1, 1, 1},
+		{"tip", testSNPs(100, 200, 400), everywhere, "a", 1, na, 1},
+		{"branch without SNPs below", testSNPs(100, 300), everywhere, "U106", 1, na, 1},
+		{"no BED file", testSNPs(100, 200), nil, "L21", 1, na, 1},
+		{"off path", testSNPs(100, 200, 300), everywhere, "L21", 2.0 / 3, 1, 2.0 / 3},
+		{"root only", testSNPs(100), everywhere, "root", 1, 1, 1},
+		{"child not callable", testSNPs(100, 200), snp.BEDRegions{{Start: 0, End: 300}}, "L21", 1, na, 1},
+	}
+	tree := placeTree()
+	for _, test := range tests {
+		p := tree.Place(NewCalls(test.derived, nil, nil, test.callable, nil))
+		if p.Node.Label != test.node {
+			t.Errorf("%s: placed on %s, want %s", test.name, p.Node.Label, test.node)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"consistency", p.Consistency, test.consistency},
+			{"downstream", p.Downstream, test.downstream},
+			{"confidence", p.Confidence, test.confidence},
+		} {
+			if math.IsNaN(v.got) != math.IsNaN(v.want) || math.Abs(v.got-v.want) > 1e-9 {
+				t.Errorf("%s: %s %g, want %g", test.name, v.name, v.got, v.want)
+			}
+		}
+	}
+}
+
+func TestCallsState(t *testing.T) {
+	db := snp.NewDB()
+	db.Add(snp.DBRecord{Key: snp.SNP{Pos: 200, Ref: "A", Alt: "G"}, Name: "L21"})
+	s := snp.SNP{Pos: 200, Ref: "A", Alt: "G"}
+	tests := []struct {
+		name     string
+		derived  snp.SNPs
+		callable snp.BEDRegions
+		db       *snp.DB
+		state    snp.Polarity
+	}{
+		{"derived call", snp.SNPs{s}, everywhere, nil, snp.Derived},
+		{"callable without call", nil, everywhere, nil, snp.Ancestral},
+		{"not callable", nil, nil, nil, snp.Unknown},
+		{"reference derived", snp.SNPs{{Pos: 200, Ref: "G", Alt: "A"}}, everywhere, nil, snp.Ancestral},
+		{"other allele", snp.SNPs{{Pos: 200, Ref: "A", Alt: "T"}}, everywhere, nil, snp.Unknown},
+		{"polarized derived", snp.SNPs{{Pos: 200, Ref: "G", Alt: "G"}}, everywhere, db, snp.Derived},
+		{"polarized ancestral", snp.SNPs{{Pos: 200, Ref: "G", Alt: "A"}}, everywhere, db, snp.Ancestral},
+		{"polarized without call", nil, everywhere, db, snp.Unknown},
+	}
+	for _, test := range tests {
+		c := NewCalls(test.derived, nil, nil, test.callable, test.db)
+		if state := c.State(s); state != test.state {
+			t.Errorf("%s: got %v, want %v", test.name, state, test.state)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		derived        snp.SNPs
+		node           string
+		splits         []string
+		conflicts      []string
+		sharedPrivates []string
+		novel          int
+	}{
+		{
+			name:    "fits",
+			derived: testSNPs(100, 200, 900),
+			node:    "L21",
+			novel:   1,
+		},
+		{
+			name:           "shares private SNP",
+			derived:        testSNPs(100, 400),
+			node:           "a",
+			conflicts:      []string{"L21"},
+			sharedPrivates: []string{"a"},
+		},
+		{
+			name:      "derived on other branch",
+			derived:   testSNPs(100, 200, 300, 500),
+			node:      "U106",
+			conflicts: []string{"L21"},
+		},
+		{
+			name:    "splits branch",
+			derived: testSNPs(100, 500),
+			node:    "U106",
+			splits:  []string{"U106"},
+		},
+	}
+	annotations := snp.Annotations{
+		testSNP(100): {Name: "M269"},
+		testSNP(200): {Name: "L21"},
+		testSNP(300): {Name: "U106"},
+	}
+	tree := Build(testMatrix([]kitData{
+		{name: "a", snps: []int{100, 200, 400}},
+		{name: "b", snps: []int{100, 200}},
+		{name: "c", snps: []int{100, 300, 500}},
+		{name: "d", snps: []int{100, 300, 500, 600}},
+	}), annotations)
+	for _, test := range tests {
+		c := tree.Check(NewCalls(test.derived, nil, nil, everywhere, nil))
+		if c.Placement.Node.Label != test.node {
+			t.Errorf("%s: placed on %s, want %s", test.name, c.Placement.Node.Label, test.node)
+		}
+		compareLabels(t, test.name+" splits", c.Splits, test.splits)
+		compareLabels(t, test.name+" conflicts", c.Conflicts, test.conflicts)
+		compareLabels(t, test.name+" shared privates", c.SharedPrivates, test.sharedPrivates)
+		if len(c.Novel) != test.novel {
+			t.Errorf("%s: %d novel SNPs, want %d", test.name, len(c.Novel), test.novel)
+		}
+	}
+}
+
+// compareLabels compares the labels of the branches of calls with want.
+func compareLabels(t *testing.T, name string, calls []BranchCalls, want []string) {
+	t.Helper()
+	var got []string
+	for _, bc := range calls {
+		got = append(got, bc.Node.Label)
+	}
+	if len(got) != len(want) {
+		t.Errorf("%s: got %v, want %v", name, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestCheckPolarized(t *testing.T) {
+	// The reference carries the derived allele G of L21 at 200,
+	// so that kits a, b and d have no call and kit c has a call of the
+	// ancestral allele.
+	db := snp.NewDB()
+	db.Add(snp.DBRecord{Key: snp.SNP{Pos: 100, Ref: "C", Alt: "T"}, Name: "M269"})
+	db.Add(snp.DBRecord{Key: snp.SNP{Pos: 200, Ref: "A", Alt: "G"}, Name: "L21"})
+	m269 := snp.SNP{Pos: 100, Ref: "C", Alt: "T"}
+	ancestralL21 := snp.SNP{Pos: 200, Ref: "G", Alt: "A"}
+	private := snp.SNP{Pos: 300, Ref: "A", Alt: "C"}
+	sets := []snp.SNPs{{m269}, {m269}, {m269, ancestralL21}, {m269, private}}
+	callable := []snp.BEDRegions{everywhere, everywhere, everywhere, everywhere}
+	sets = db.PolarizeKits(sets, callable)
+	annotations := snp.Annotations{
+		m269:                                  {Name: "M269"},
+		snp.SNP{Pos: 200, Ref: "A", Alt: "G"}: {Name: "L21"},
+	}
+	tree := Build(snp.NewMatrix([]string{"a", "b", "c", "d"}, sets, callable), annotations)
+
+	tests := []struct {
+		name           string
+		derived        snp.SNPs
+		node           string
+		sharedPrivates []string
+		novel          int
+	}{
+		{"reference allele", snp.SNPs{m269}, "root", nil, 0},
+		{"ancestral allele", snp.SNPs{m269, ancestralL21}, "root", nil, 0},
+		{"private SNP of other kit", snp.SNPs{m269, private}, "d", []string{"d"}, 0},
+		{"novel SNP", snp.SNPs{m269, {Pos: 400, Ref: "C", Alt: "T"}}, "root", nil, 1},
+	}
+	for _, test := range tests {
+		c := tree.Check(NewCalls(test.derived, nil, nil, everywhere, db))
+		if c.Placement.Node.Label != test.node {
+			t.Errorf("%s: placed on %s, want %s", test.name, c.Placement.Node.Label, test.node)
+		}
+		compareLabels(t, test.name+" conflicts", c.Conflicts, nil)
+		compareLabels(t, test.name+" shared privates", c.SharedPrivates, test.sharedPrivates)
+		if len(c.Novel) != test.novel {
+			t.Errorf("%s: novel SNPs %v, want %d", test.name, c.Novel, test.novel)
+		}
+	}
+	if got, _ := tree.newick(false); got != "((a:0,b:0,d:1)L21:1,c:0)root:1;" {
+		t.Errorf("got tree %s", got)
+	}
+}
@@ -0,0 +1,190 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Style contains the options for rendering trees as graphics.
+type Style struct {
+	// MaxSNPs is the maximum number of SNPs shown for a branch.
+	// Larger blocks of SNPs are collapsed to the first SNPs and the
+	// number of the remaining ones. If it is 0, all SNPs are shown.
+	MaxSNPs int
+	// Ages shows the estimated ages of the branches if they are known.
+	Ages bool
+}
+
+// WriteDOT writes the tree in the DOT language of Graphviz.
+// Inner branches are boxes with the branch label and its SNPs.
+// Kits are ellipses.
+func (t *Tree) WriteDOT(w io.Writer, style Style) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph phylosnip {\r\n")
+	bw.WriteString("\trankdir=LR;\r\n")
+	bw.WriteString("\tnode [shape=box, fontname=\"Helvetica\", fontsize=10];\r\n")
+	ids := make(map[*Node]int)
+	t.Root.Walk(func(node *Node, depth int) {
+		id := len(ids)
+		ids[node] = id
+		lines := []string{node.Label}
+		if age := t.ageLabel(node, style); age != "" {
+			lines = append(lines, age)
+		}
+		if snps := t.snpLabel(node, style); snps != "" {
+			lines = append(lines, snps)
+		}
+		for i, line := range lines {
+			lines[i] = dotEscape(line)
+		}
+		shape := ""
+		if node.Kit != "" {
+			shape = ", shape=ellipse"
+		}
+		fmt.Fprintf(bw, "\tn%d [label=\"%s\"%s];\r\n", id, strings.Join(lines, "\\n"), shape)
+		if node.Parent != nil {
+			fmt.Fprintf(bw, "\tn%d -> n%d;\r\n", ids[node.Parent], id)
+		}
+	})
+	bw.WriteString("}\r\n")
+	return bw.Flush()
+}
+
+// dotEscape escapes quotes and backslashes in DOT strings.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// Dimensions of SVG drawings in pixels.
+const (
+	svgRowHeight = 40
+	svgCharWidth = 7
+	svgMargin    = 20
+	svgMinColumn = 100
+)
+
+// WriteSVG writes the tree as an SVG drawing. The root is on the left
+// and the kits are on the right. Each branch is labeled with its name
+// and optional age above and its SNPs below the line.
+func (t *Tree) WriteSVG(w io.Writer, style Style) error {
+	// Compute the layout. Tips are placed in rows,
+	// inner branches in the middle of their children.
+	column := svgMinColumn
+	maxDepth, tips := 0, 0
+	t.Root.Walk(func(node *Node, depth int) {
+		for _, text := range []string{t.branchTitle(node, style), t.snpLabel(node, style)} {
+			if width := len(text)*svgCharWidth + svgMargin; width > column {
+				column = width
+			}
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		if node.IsTip() {
+			tips++
+		}
+	})
+	x := make(map[*Node]int)
+	y := make(map[*Node]int)
+	row := 0
+	var layout func(node *Node, depth int)
+	layout = func(node *Node, depth int) {
+		x[node] = svgMargin + (depth+1)*column
+		if node.IsTip() {
+			y[node] = svgMargin + row*svgRowHeight + svgRowHeight/2
+			row++
+			return
+		}
+		for _, c := range node.Children {
+			layout(c, depth+1)
+		}
+		y[node] = (y[node.Children[0]] + y[node.Children[len(node.Children)-1]]) / 2
+	}
+	layout(t.Root, 0)
+	maxKit := 0
+	t.Root.Walk(func(node *Node, depth int) {
+		if node.IsTip() && len(node.Label) > maxKit {
+			maxKit = len(node.Label)
+		}
+	})
+	width := 2*svgMargin + (maxDepth+1)*column + maxKit*svgCharWidth + svgMargin
+	height := 2*svgMargin + tips*svgRowHeight
+
+	// Draw the tree.
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\r\n")
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"Helvetica, Arial, sans-serif\" font-size=\"12\">\r\n", width, height)
+	bw.WriteString("<g stroke=\"black\" stroke-width=\"1\" fill=\"none\">\r\n")
+	t.Root.Walk(func(node *Node, depth int) {
+		x0 := x[node] - column
+		if node.Parent != nil {
+			x0 = x[node.Parent]
+			fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"/>\r\n", x0, y[node.Parent], x0, y[node])
+		}
+		fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"/>\r\n", x0, y[node], x[node], y[node])
+	})
+	bw.WriteString("</g>\r\n")
+	t.Root.Walk(func(node *Node, depth int) {
+		x0 := x[node] - column + svgCharWidth/2
+		if node.Parent != nil {
+			x0 = x[node.Parent] + svgCharWidth/2
+		}
+		if node.Kit != "" {
+			fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\" dominant-baseline=\"middle\" font-weight=\"bold\">%s</text>\r\n",
+				x[node]+svgCharWidth/2, y[node], xmlEscape(node.Label))
+			if title := t.ageLabel(node, style); title != "" {
+				fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\">%s</text>\r\n", x0, y[node]-4, xmlEscape(title))
+			}
+		} else {
+			fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\">%s</text>\r\n", x0, y[node]-4, xmlEscape(t.branchTitle(node, style)))
+		}
+		if snps := t.snpLabel(node, style); snps != "" {
+			fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\" font-size=\"10\" fill=\"dimgray\">%s</text>\r\n", x0, y[node]+13, xmlEscape(snps))
+		}
+	})
+	bw.WriteString("</svg>\r\n")
+	return bw.Flush()
+}
+
+// xmlEscape escapes special characters in XML text.
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// branchTitle returns the label of a branch followed by its age.
+func (t *Tree) branchTitle(n *Node, style Style) string {
+	if age := t.ageLabel(n, style); age != "" {
+		return n.Label + " " + age
+	}
+	return n.Label
+}
+
+// ageLabel returns the age of a branch and its confidence interval
+// or an empty string if ages are not shown or unknown.
+func (t *Tree) ageLabel(n *Node, style Style) string {
+	if !style.Ages || n.Age == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.0f (%.0f-%.0f)", n.Age.Years, n.Age.Lower, n.Age.Upper)
+}
+
+// snpLabel returns the SNPs of a branch separated by commas.
+// Blocks with more than style.MaxSNPs SNPs are collapsed.
+func (t *Tree) snpLabel(n *Node, style Style) string {
+	var markers []string
+	switch {
+	case len(n.SNPs) > 0:
+		for _, s := range n.SNPs {
+			markers = append(markers, t.marker(s))
+		}
+	default:
+		markers = n.Names
+	}
+	if style.MaxSNPs > 0 && len(markers) > style.MaxSNPs {
+		more := len(markers) - style.MaxSNPs
+		markers = append(markers[:style.MaxSNPs:style.MaxSNPs], fmt.Sprintf("+%d more", more))
+	}
+	return strings.Join(markers, ", ")
+}
@@ -0,0 +1,105 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// renderTree returns a tree with SNP names, a label that must be
+// escaped and an age for the root.
+func renderTree(t *testing.T) *Tree {
+	t.Helper()
+	tree, err := ReadNewick(strings.NewReader(`((k1:1,'k"2':1)'L21 & S145':2,k3:1)root:1;`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Root.Children[0].Names = []string{"L21", "DF13", "Z1", "<Z2>"}
+	tree.Root.Age = &Age{Years: 4500.4, Lower: 3900, Upper: 5200.6}
+	return tree
+}
+
+func TestSNPLabel(t *testing.T) {
+	tree := renderTree(t)
+	n := tree.Root.Children[0]
+	tests := []struct {
+		maxSNPs int
+		want    string
+	}{
+		{0, "L21, DF13, Z1, <Z2>"},
+		{4, "L21, DF13, Z1, <Z2>"},
+		{2, "L21, DF13, +2 more"},
+		{1, "L21, +3 more"},
+	}
+	for _, test := range tests {
+		if got := tree.snpLabel(n, Style{MaxSNPs: test.maxSNPs}); got != test.want {
+			t.Errorf("MaxSNPs=%d: got %q, want %q", test.maxSNPs, got, test.want)
+		}
+		if len(n.Names) != 4 {
+			t.Fatalf("MaxSNPs=%d: names modified to %v", test.maxSNPs, n.Names)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b bytes.Buffer
+	if err := renderTree(t).WriteDOT(&b, Style{MaxSNPs: 2, Ages: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "digraph phylosnip {\r\n" +
+		"\trankdir=LR;\r\n" +
+		"\tnode [shape=box, fontname=\"Helvetica\", fontsize=10];\r\n" +
+		"\tn0 [label=\"root\\n4500 (3900-5201)\"];\r\n" +
+		"\tn1 [label=\"L21 & S145\\nL21, DF13, +2 more\"];\r\n" +
+		"\tn0 -> n1;\r\n" +
+		"\tn2 [label=\"k1\", shape=ellipse];\r\n" +
+		"\tn1 -> n2;\r\n" +
+		"\tn3 [label=\"k\\\"2\", shape=ellipse];\r\n" +
+		"\tn1 -> n3;\r\n" +
+		"\tn4 [label=\"k3\", shape=ellipse];\r\n" +
+		"\tn0 -> n4;\r\n" +
+		"}\r\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := renderTree(t).WriteSVG(&b, Style{Ages: true}); err != nil {
+		t.Fatal(err)
+	}
+	// The drawing must be well-formed XML with one horizontal line
+	// per branch, one vertical line per child and the escaped texts.
+	var lines int
+	var texts []string
+	d := xml.NewDecoder(&b)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG, %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Local == "line" {
+				lines++
+			}
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				texts = append(texts, s)
+			}
+		}
+	}
+	if lines != 9 {
+		t.Errorf("got %d lines, want 9", lines)
+	}
+	want := []string{"root 4500 (3900-5201)", "L21 & S145", "L21, DF13, Z1, <Z2>", "k1", `k"2`, "k3"}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("got texts %q, want %q", texts, want)
+	}
+}
@@ -0,0 +1,226 @@
+// Package tree provides phylogenetic trees of Y-chromosome SNPs.
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yogischogi/phylosnip/snp"
+)
+
+// Node is a branch of a phylogenetic tree. The SNPs of a node are
+// the mutations on the branch leading from the parent to the node.
+// Tips of a tree built from kits represent the kits.
+type Node struct {
+	// Label is the name of the branch, for example a representative
+	// SNP name. For tips it is the name of the kit.
+	Label string
+	// SNPs are the mutations on the branch, sorted by position.
+	SNPs snp.SNPs
+	// Names are the names of the mutations on the branch. They may
+	// contain names of SNPs with unknown positions.
+	Names []string
+	// Kit is the name of the kit at a tip and empty otherwise.
+	Kit string
+	// Callable is the number of base pairs in which the SNPs of the
+	// branch could be detected or 0 if it is unknown.
+	Callable int
+	// Length is the branch length of a tree read from a file.
+	// It is used if the number of SNPs is unknown.
+	Length float64
+	// Age is the estimated age of the branch or nil if it is unknown.
+	Age      *Age
+	Parent   *Node
+	Children []*Node
+	// regions are the callable regions of the branch
+	// or nil if they are unknown.
+	regions snp.BEDRegions
+}
+
+// Tree is a phylogenetic tree.
+type Tree struct {
+	Root *Node
+	// Conflicts are SNPs that are incompatible with the tree.
+	Conflicts []Conflict
+	// Annotations contains names and comments of the SNPs.
+	Annotations snp.Annotations
+}
+
+// Conflict is an SNP that does not fit into the tree.
+type Conflict struct {
+	SNP snp.SNP
+	// Kits are the kits that are derived for the SNP.
+	Kits []string
+}
+
+// AddChild appends child to the children of n.
+func (n *Node) AddChild(child *Node) {
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// IsTip returns true if n has no children.
+func (n *Node) IsTip() bool {
+	return len(n.Children) == 0
+}
+
+// SNPCount returns the number of mutations on the branch.
+func (n *Node) SNPCount() int {
+	if len(n.Names) > len(n.SNPs) {
+		return len(n.Names)
+	}
+	return len(n.SNPs)
+}
+
+// Walk calls f for n and all its descendants in preorder.
+// depth is the distance from n.
+func (n *Node) Walk(f func(node *Node, depth int)) {
+	n.walk(f, 0)
+}
+
+func (n *Node) walk(f func(node *Node, depth int), depth int) {
+	f(n, depth)
+	for _, c := range n.Children {
+		c.walk(f, depth+1)
+	}
+}
+
+// Kits returns the names of all kits in the subtree of n.
+func (n *Node) Kits() []string {
+	var kits []string
+	n.Walk(func(node *Node, depth int) {
+		if node.Kit != "" {
+			kits = append(kits, node.Kit)
+		}
+	})
+	return kits
+}
+
+// Path returns the nodes from the root to n.
+func (n *Node) Path() []*Node {
+	var path []*Node
+	for node := n; node != nil; node = node.Parent {
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// SetCallable sets the callable length of all branches. The callable
+// regions of a branch are the regions that are callable in all kits
+// of its subtree. callable maps kit names to their callable regions.
+// If a kit of the subtree has no callable regions, the callable
+// length of the branch is unknown.
+func (t *Tree) SetCallable(callable map[string]snp.BEDRegions) {
+	t.Root.setCallable(callable)
+}
+
+// setCallable sets the callable length of n and its descendants and
+// returns the callable regions of n or nil if they are unknown.
+func (n *Node) setCallable(callable map[string]snp.BEDRegions) snp.BEDRegions {
+	var regions snp.BEDRegions
+	known := true
+	if n.Kit != "" {
+		var r snp.BEDRegions
+		r, known = callable[n.Kit]
+		regions = append(regions, r...)
+	}
+	for i, c := range n.Children {
+		r := c.setCallable(callable)
+		switch {
+		case r == nil:
+			known = false
+		case i == 0 && n.Kit == "":
+			regions = r
+		default:
+			regions = regions.Intersection(r)
+		}
+	}
+	n.Callable, n.regions = 0, nil
+	if !known || regions == nil {
+		return nil
+	}
+	regions.Normalize()
+	n.Callable, n.regions = regions.Length(), regions
+	return regions
+}
+
+// Find returns the first node in preorder with the given label
+// or nil if there is none.
+func (t *Tree) Find(label string) *Node {
+	var result *Node
+	t.Root.Walk(func(node *Node, depth int) {
+		if result == nil && node.Label == label {
+			result = node
+		}
+	})
+	return result
+}
+
+// Write writes the tree as indented text. Each line contains a branch
+// with its label, the number of SNPs or the branch length if the
+// number is unknown, the estimated age if it is known and the SNPs
+// with their names.
+// Conflicting SNPs are listed at the end.
+func (t *Tree) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	t.Root.Walk(func(node *Node, depth int) {
+		bw.WriteString(strings.Repeat("  ", depth))
+		bw.WriteString(node.Label)
+		if node.Kit != "" && node.Kit != node.Label {
+			bw.WriteString(" (" + node.Kit + ")")
+		}
+		if count := node.SNPCount(); count > 0 || node.Length == 0 {
+			fmt.Fprintf(bw, " [%d SNPs]", count)
+		} else {
+			fmt.Fprintf(bw, " [length %g]", node.Length)
+		}
+		if node.Age != nil {
+			fmt.Fprintf(bw, " [age %.0f, %.0f-%.0f]", node.Age.Years, node.Age.Lower, node.Age.Upper)
+		}
+		// Names without SNPs are listed after the SNPs.
+		var list []string
+		if len(node.SNPs) > 0 {
+			list = append(list, t.snpList(node.SNPs))
+		}
+		linked := make(map[string]bool)
+		for _, s := range node.SNPs {
+			linked[t.Annotations[s].Name] = true
+		}
+		for _, name := range node.Names {
+			if !linked[name] {
+				list = append(list, name)
+			}
+		}
+		if len(list) > 0 {
+			bw.WriteString(": " + strings.Join(list, "; "))
+		}
+		bw.WriteString("\r\n")
+	})
+	if len(t.Conflicts) > 0 {
+		fmt.Fprintf(bw, "Conflicts [%d SNPs]\r\n", len(t.Conflicts))
+		for _, c := range t.Conflicts {
+			bw.WriteString("  " + t.snpList(snp.SNPs{c.SNP}))
+			bw.WriteString(": " + strings.Join(c.Kits, ", ") + "\r\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// snpList formats SNPs as Pos,Ref,Alt followed by the SNP name
+// if there is one. The SNPs are separated by semicolons.
+func (t *Tree) snpList(snps snp.SNPs) string {
+	parts := make([]string, len(snps))
+	for i, s := range snps {
+		parts[i] = strconv.Itoa(s.Pos) + "," + s.Ref + "," + s.Alt
+		if name := t.Annotations[s].Name; name != "" {
+			parts[i] += " " + name
+		}
+	}
+	return strings.Join(parts, "; ")
+}